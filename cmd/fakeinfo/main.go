@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"song-lib/internal/fakeinfo"
+	"song-lib/internal/lib/logs"
+	"time"
+)
+
+// Заменитель внешнего API для локальной разработки:
+//
+//	go run ./cmd/fakeinfo -addr :8081 -latency 200ms -error-rate 0.1
+func main() {
+	addr := flag.String("addr", "localhost:8081", "address to listen on")
+	fixtures := flag.String("fixtures", "", "path to JSON fixtures (built-in set if empty)")
+	latency := flag.Duration("latency", 0, "delay before every response")
+	errorRate := flag.Float64("error-rate", 0, "share of requests (0..1) answered with -error-status")
+	errorStatus := flag.Int("error-status", http.StatusInternalServerError, "status code of injected errors")
+	failFirst := flag.Int("fail-first", 0, "number of first requests that fail")
+	malformedRate := flag.Float64("malformed-rate", 0, "share of requests (0..1) answered with malformed JSON")
+	authHeader := flag.String("auth-header", "Authorization", "header with the auth token")
+	authToken := flag.String("auth-token", "", "required auth token (not checked if empty)")
+	logLevel := flag.String("log-level", "info", "log level: debug, info, error")
+	flag.Parse()
+
+	log := logs.InitLogger(*logLevel)
+
+	songs := fakeinfo.DefaultFixtures()
+	if *fixtures != "" {
+		var err error
+		songs, err = fakeinfo.LoadFixtures(*fixtures)
+		if err != nil {
+			log.Error("failed to load fixtures", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	server := fakeinfo.New(log, songs, fakeinfo.Options{
+		Latency:       *latency,
+		ErrorRate:     *errorRate,
+		ErrorStatus:   *errorStatus,
+		FailFirst:     *failFirst,
+		MalformedRate: *malformedRate,
+		AuthHeader:    *authHeader,
+		AuthToken:     *authToken,
+	})
+
+	log.Info("starting fake external api", slog.String("address", *addr), slog.Int("fixtures", len(songs)))
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if err := srv.ListenAndServe(); err != nil {
+		log.Error("failed to start server", "error", err)
+		os.Exit(1)
+	}
+}
@@ -15,17 +15,29 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/albums": {
             "get": {
-                "description": "Get songs with pagination support",
+                "description": "Get albums in alphabetical order with the number of their tracks",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Get song with pagination",
+                "summary": "Get albums",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only albums of the artist",
+                        "name": "artist_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the album title",
+                        "name": "title",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "default": 1,
@@ -36,7 +48,7 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Number of songs per page",
+                        "description": "Number of albums per page, at most 100",
                         "name": "limit",
                         "in": "query"
                     }
@@ -45,28 +57,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Song"
-                            }
+                            "$ref": "#/definitions/album.ListResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid request",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to get songs",
+                        "description": "Failed to get albums",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Add a new song to the library and fetch additional details from an external API",
+                "description": "Add an album of an artist. Albums are also created automatically from external API details",
                 "consumes": [
                     "application/json"
                 ],
@@ -74,67 +83,121 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Add a new song",
+                "summary": "Add an album",
                 "parameters": [
                     {
-                        "description": "Song details",
-                        "name": "song",
+                        "description": "Album",
+                        "name": "album",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/add.Request"
+                            "$ref": "#/definitions/album.Request"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Song added successfully",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/add.Response"
+                            "$ref": "#/definitions/album.Response"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Album already exists or artist does not exist",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to add song",
+                        "description": "Failed to add album",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     }
                 }
             }
         },
-        "/songs/{id}": {
+        "/albums/{id}": {
+            "get": {
+                "description": "Get an album with its artist and the number of tracks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Albums"
+                ],
+                "summary": "Get an album",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Album"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Album not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get album",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
             "put": {
-                "description": "Update a song in the library by its ID",
+                "description": "Update an album by its ID. The artist of an album with tracks cannot be changed",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Update a song",
+                "summary": "Update an album",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Song ID",
+                        "description": "Album ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated song details",
-                        "name": "song",
+                        "description": "Album",
+                        "name": "album",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/up.Request"
+                            "$ref": "#/definitions/album.Request"
                         }
                     }
                 ],
@@ -142,36 +205,48 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/album.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "404": {
-                        "description": "Song not found",
+                        "description": "Album not found",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The artist already has an album with this title, artist does not exist or album has tracks",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to update song",
+                        "description": "Failed to update album",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a song from the library by its ID",
+                "description": "Delete an album. Its songs stay in the library without album and track numbers",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Delete a song",
+                "summary": "Delete an album",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Song ID",
+                        "description": "Album ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -181,42 +256,218 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/del.Response"
+                            "$ref": "#/definitions/album.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "404": {
-                        "description": "Song not found",
+                        "description": "Album not found",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete song",
+                        "description": "Failed to delete album",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     }
                 }
             }
         },
-        "/songs/{id}/text": {
+        "/albums/{id}/tracks": {
             "get": {
-                "description": "Get song text divided by verses with pagination support",
+                "description": "Get the album and its songs ordered by disc and track number, songs without a track number go last on their disc",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Get song lyrics with pagination",
+                "summary": "Get album tracks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/album.TracksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Album not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tracks",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/albums/{id}/tracks/{song_id}": {
+            "put": {
+                "description": "Place a song on the album or move it to another disc or track number. A song belongs to at most one album\nand only to an album of its artist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Albums"
+                ],
+                "summary": "Add a song to an album",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Disc and track number",
+                        "name": "track",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/album.TrackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/album.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or song of another artist, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Album or song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Track number is taken",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set track",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a song from the album. The song stays in the library",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Albums"
+                ],
+                "summary": "Remove a song from an album",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Album ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/album.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song is not on the album",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove track",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/artists": {
+            "get": {
+                "description": "Get artists in alphabetical order with the number of their songs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Get artists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the artist name",
+                        "name": "name",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "default": 1,
@@ -226,8 +477,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "default": 3,
-                        "description": "Number of verses per page",
+                        "default": 10,
+                        "description": "Number of artists per page, at most 100",
                         "name": "limit",
                         "in": "query"
                     }
@@ -236,25 +487,2119 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/text.Response"
+                            "$ref": "#/definitions/artist.ListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get artists",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add an artist. Artists are also created automatically for new song groups",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Add an artist",
+                "parameters": [
+                    {
+                        "description": "Artist",
+                        "name": "artist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/artist.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/artist.Response"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid request, failed fields are listed in errors",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Song not found",
+                    "409": {
+                        "description": "Artist already exists",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to get song text",
+                        "description": "Failed to add artist",
                         "schema": {
-                            "$ref": "#/definitions/resp.Response"
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/artists/{id}": {
+            "get": {
+                "description": "Get an artist with the number of their songs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Get an artist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Artist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Artist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Artist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get artist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Rename an artist. The group of all their songs changes too",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Rename an artist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Artist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Artist",
+                        "name": "artist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/artist.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/artist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Artist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Another artist has this name",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update artist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an artist. Artists that still have songs cannot be deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Delete an artist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Artist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/artist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Artist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Artist still has songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete artist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/artists/{id}/songs": {
+            "get": {
+                "description": "Get songs of an artist. Accepts the same filters, sorting and pagination as GET /songs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Get songs of an artist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Artist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the song name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. \\",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only songs with the tag, repeat for several tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "Songs with all of the tags or with any of them",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Comma-separated sort fields (id, group, name, release_date), a leading minus sorts descending, ties are broken by id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from next_cursor or prev_cursor of the previous response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, prefer cursor for deep pages",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of songs per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Count total matching songs, false skips the COUNT query",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/get.Response"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the next and previous pages (RFC 8288)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Artist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/status": {
+            "get": {
+                "description": "Get the circuit breaker state of every external song details provider",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrichment"
+                ],
+                "summary": "Get external API status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/status.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists": {
+            "get": {
+                "description": "Get playlists with the number of their entries, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Get playlists",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of playlists per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.ListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get playlists",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add an empty playlist. Duplicate songs are allowed unless allow_duplicates is false",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Add a playlist",
+                "parameters": [
+                    {
+                        "description": "Playlist",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add playlist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{id}": {
+            "get": {
+                "description": "Get a playlist with the number of its entries",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Get a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get playlist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the name, description and duplicate policy of a playlist.\nDuplicates cannot be disallowed while the playlist has them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Update a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playlist",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Playlist has duplicate songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update playlist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a playlist with its entries. The songs stay in the library",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Delete a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete playlist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{id}/entries": {
+            "get": {
+                "description": "Get entries of a playlist in order with their songs. Positions start from 1 and have no gaps",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Get playlist entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of entries per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.EntriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get entries",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set a new order of the playlist. entry_ids must list every entry exactly once,\nan outdated list (an entry was added or removed meanwhile) is rejected with 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Reorder a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Entry IDs in the new order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/playlist.OrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Entry IDs do not match the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reorder playlist",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Insert a song at the position, the following entries move down. Without position the song is appended",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Add a song to a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song and position",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/playlist.EntryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlaylistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Song is already in a playlist without duplicates",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add entry",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{id}/entries/{entry_id}": {
+            "delete": {
+                "description": "Remove an entry, the following entries move up. The song stays in the library",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Remove a playlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/playlist.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove entry",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Move an entry to the position, the entries in between shift by one. A position past the end moves the entry last",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Move a playlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New position",
+                        "name": "position",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/playlist.MoveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlaylistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to move entry",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Get songs matching any combination of filters. group and name match a case-insensitive substring.\nq is a search query: words and \"quoted phrases\" match group, name or text, a field prefix\n(group:, name:, song:, text:, link:) limits a term to one field, a leading minus excludes matches.\nAll conditions are combined with AND. Pages are linked by opaque next_cursor and prev_cursor\nthat keep the chosen sort order stable while songs are added. The same pages are linked\nby next and prev URLs in the body and in the Link header. No matches is an empty list,\nwith did_you_mean suggesting a similar group and name when they were searched without fuzzy",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Get songs with filters and pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the song name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. muse \\",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Released on or after the date (YYYY-MM-DD or DD.MM.YYYY)",
+                        "name": "released_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Released on or before the date (YYYY-MM-DD or DD.MM.YYYY)",
+                        "name": "released_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "group and name also match values with typos",
+                        "name": "fuzzy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) text",
+                        "name": "has_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) link",
+                        "name": "has_link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only songs with the tag, repeat for several tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "Songs with all of the tags or with any of them",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Comma-separated sort fields (id, group, name, release_date), a leading minus sorts descending, ties are broken by id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from next_cursor or prev_cursor of the previous response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, prefer cursor for deep pages",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of songs per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Count total matching songs, false skips the COUNT query",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/get.Response"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the next and previous pages (RFC 8288)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new song to the library. Additional details are fetched from an external API in the background,\nprogress is reported by the enrichment_status field",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Add a new song",
+                "parameters": [
+                    {
+                        "description": "Song details",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song accepted for enrichment",
+                        "schema": {
+                            "$ref": "#/definitions/add.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Song already exists",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/enrichment": {
+            "get": {
+                "description": "Get songs that are still waiting for external API details or failed to get them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrichment"
+                ],
+                "summary": "Get songs by enrichment status",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "failed",
+                            "done"
+                        ],
+                        "type": "string",
+                        "default": "pending",
+                        "description": "Enrichment status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of songs per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/refresh": {
+            "post": {
+                "description": "Queue songs with the given enrichment status for re-enrichment in the background",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrichment"
+                ],
+                "summary": "Refresh songs by status",
+                "parameters": [
+                    {
+                        "enum": [
+                            "failed",
+                            "done"
+                        ],
+                        "type": "string",
+                        "default": "failed",
+                        "description": "Enrichment status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/refresh.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to queue songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/search": {
+            "get": {
+                "description": "Find songs by a remembered line of lyrics, group or name. Results are ranked by relevance,\ngroup and name matches weigh more than text matches. The query supports \"quoted phrases\",\nOR and a leading minus to exclude words. snippet is the best matching verse with found words\nwrapped in \u003cmark\u003e\u003c/mark\u003e and the rest HTML-escaped, verse is its number as in GET /songs/{id}/text.\nWith fuzzy=true groups and names similar to the query are found too, e.g. Metalica",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Full-text search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. \\",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also find groups and names with typos",
+                        "name": "fuzzy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of songs per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/find.Response"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Link to the next page (RFC 8288)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search songs",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Get a song with all its details by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Get a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a song in the library by its ID. The change is saved as a new revision of the song",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Update a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author of the change, saved in the revision",
+                        "name": "X-Author",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated song details",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/up.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/up.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move a song to the trash by its ID. The song can be restored until the trash is purged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Delete a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/del.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update only the provided fields of a song using JSON Merge Patch (RFC 7396) semantics:\nabsent fields are left unchanged, null clears release_date, text or link.\nThe change is saved as a new revision of the song",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Partially update a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author of the change, saved in the revision",
+                        "name": "X-Author",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/patch.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported content type",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/refresh": {
+            "post": {
+                "description": "Re-query the external API for the song and update its release date, text and link",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrichment"
+                ],
+                "summary": "Refresh song details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/refresh.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song or song details not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to refresh song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "External API unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "External API did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/restore": {
+            "post": {
+                "description": "Restore a deleted song with its tags and its places in the album and playlists",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Restore a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found in trash",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore song",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions": {
+            "get": {
+                "description": "Get revisions of a song, newest first. Every change of group, name, release date,\ntext or link creates a revision with the full state of the song, the first revision is the added song",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Get song revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of revisions per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/revision.ListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get revisions",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/diff": {
+            "get": {
+                "description": "Get the fields changed between two revisions and a line diff of the text.\ntext is empty if the text has not changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Compare song revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Older revision number",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Newer revision number",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compare revisions",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}": {
+            "get": {
+                "description": "Get the state of a song after the change with the author and time of the change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Get a song revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get revision",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}/restore": {
+            "post": {
+                "description": "Set group, name, release date, text and link of a song from the revision.\nThe rollback is saved as a new revision, enrichment no longer changes the restored fields",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Restore a song revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author of the change, saved in the revision",
+                        "name": "X-Author",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Restored values conflict with the current data",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore revision",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/tags": {
+            "put": {
+                "description": "Replace the tags of a song: missing tags are attached, tags not in the list are detached.\nTags are case-insensitive and stored in lower case, an empty list removes all tags",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Set song tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tags of the song",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/tag.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, failed fields are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set tags",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/text": {
+            "get": {
+                "description": "Get song text divided by verses with pagination support",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Get song lyrics with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 3,
+                        "description": "Number of verses per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/text.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get song text",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/suggest": {
+            "get": {
+                "description": "Get distinct groups or song names starting with the prefix (case-insensitive), most frequent first,\nwith the number of songs for each. Responses are cacheable: Cache-Control allows clients and proxies\nto reuse them for a short time and an ETag lets them revalidate with If-None-Match",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Autocomplete groups and song names",
+                "parameters": [
+                    {
+                        "enum": [
+                            "group",
+                            "name"
+                        ],
+                        "type": "string",
+                        "description": "Field to complete",
+                        "name": "field",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Beginning of the value",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of suggestions, at most 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/suggest.Response"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "public, max-age=\u003cSUGGEST_MAX_AGE\u003e"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get suggestions",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Get the most used tags with the number of songs, most used first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Get tag cloud",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of tags, at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tag.CloudResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tags",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "description": "Get deleted songs, recently deleted first. Songs are purged from the trash after the retention period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Get trash",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of songs per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/trash.ListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get trash",
+                        "schema": {
+                            "$ref": "#/definitions/resp.Problem"
                         }
                     }
                 }
@@ -264,39 +2609,726 @@ const docTemplate = `{
     "definitions": {
         "add.Request": {
             "type": "object",
-            "required": [
-                "group",
-                "song"
-            ],
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "add.Response": {
+            "type": "object",
+            "properties": {
+                "enrichment_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "album.ListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Album"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "album.Request": {
+            "type": "object",
+            "required": [
+                "artist_id",
+                "title"
+            ],
+            "properties": {
+                "artist_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "cover": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "album.Response": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "album.TrackRequest": {
+            "type": "object",
+            "properties": {
+                "disc_number": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "track_number": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "album.TracksResponse": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "$ref": "#/definitions/models.Album"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                }
+            }
+        },
+        "artist.ListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Artist"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "artist.Request": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "artist.Response": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "del.Response": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "enrichment.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "retry_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "enrichment.ProviderStatus": {
+            "type": "object",
+            "properties": {
+                "breaker": {
+                    "$ref": "#/definitions/enrichment.BreakerStatus"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "find.Response": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongSearchResult"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "get.Response": {
+            "type": "object",
+            "properties": {
+                "did_you_mean": {
+                    "description": "DidYouMean - похожие группа и название, если по group и name ничего не нашлось",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SongSuggestion"
+                        }
+                    ]
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Album": {
+            "type": "object",
+            "properties": {
+                "artist": {
+                    "type": "string"
+                },
+                "artist_id": {
+                    "type": "integer"
+                },
+                "cover": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "track_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Artist": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "song_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DiffLine": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Playlist": {
+            "type": "object",
+            "properties": {
+                "allow_duplicates": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "entry_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlaylistEntry": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                }
+            }
+        },
+        "models.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "song_id": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffLine"
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Song": {
+            "type": "object",
+            "properties": {
+                "album_id": {
+                    "description": "AlbumID, DiscNumber и TrackNumber - место песни в альбоме, nil - песня не в альбоме",
+                    "type": "integer"
+                },
+                "artist_id": {
+                    "type": "integer"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt - время переноса в корзину, nil - песня не в корзине",
+                    "type": "string"
+                },
+                "disc_number": {
+                    "type": "integer"
+                },
+                "enriched_at": {
+                    "type": "string"
+                },
+                "enrichment_status": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "description": "Provenance - источник каждого поля: release_date, text, link, album",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Tags - теги песни по алфавиту",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                },
+                "track_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongRevision": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "song_id": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SongSearchResult": {
+            "type": "object",
+            "properties": {
+                "album_id": {
+                    "description": "AlbumID, DiscNumber и TrackNumber - место песни в альбоме, nil - песня не в альбоме",
+                    "type": "integer"
+                },
+                "artist_id": {
+                    "type": "integer"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt - время переноса в корзину, nil - песня не в корзине",
+                    "type": "string"
+                },
+                "disc_number": {
+                    "type": "integer"
+                },
+                "enriched_at": {
+                    "type": "string"
+                },
+                "enrichment_status": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "description": "Provenance - источник каждого поля: release_date, text, link, album",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Tags - теги песни по алфавиту",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                },
+                "track_number": {
+                    "type": "integer"
+                },
+                "verse": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongSuggestion": {
+            "type": "object",
             "properties": {
                 "group": {
                     "type": "string"
                 },
-                "song": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "add.Response": {
+        "models.Suggestion": {
             "type": "object",
             "properties": {
-                "error": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
                     "type": "string"
+                }
+            }
+        },
+        "models.TagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "msg": {
+                "name": {
                     "type": "string"
+                }
+            }
+        },
+        "patch.Request": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
                 },
-                "status": {
+                "link": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "text": {
                     "type": "string"
                 }
             }
         },
-        "del.Response": {
+        "playlist.EntriesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaylistEntry"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "playlist.EntryRequest": {
+            "type": "object",
+            "required": [
+                "song_id"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "song_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "playlist.ListResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Playlist"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
                     "type": "string"
                 },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "playlist.MoveRequest": {
+            "type": "object",
+            "required": [
+                "position"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "playlist.OrderRequest": {
+            "type": "object",
+            "required": [
+                "entry_ids"
+            ],
+            "properties": {
+                "entry_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "playlist.Request": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "allow_duplicates": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "playlist.Response": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
                 "msg": {
                     "type": "string"
                 },
@@ -305,40 +3337,142 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Song": {
+        "refresh.BulkResponse": {
             "type": "object",
             "properties": {
-                "group": {
+                "queued": {
+                    "type": "integer"
+                },
+                "status": {
                     "type": "string"
+                }
+            }
+        },
+        "refresh.Response": {
+            "type": "object",
+            "properties": {
+                "song": {
+                    "$ref": "#/definitions/models.Song"
                 },
-                "id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "resp.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
                 },
-                "link": {
+                "message": {
                     "type": "string"
                 },
-                "name": {
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "resp.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
                     "type": "string"
                 },
-                "release_date": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/resp.FieldError"
+                    }
+                },
+                "instance": {
                     "type": "string"
                 },
-                "text": {
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "resp.Response": {
+        "revision.ListResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongRevision"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
                     "type": "string"
                 },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "status.Response": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/enrichment.ProviderStatus"
+                    }
+                },
                 "status": {
                     "type": "string"
                 }
             }
         },
+        "suggest.Response": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                }
+            }
+        },
+        "tag.CloudResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TagCount"
+                    }
+                }
+            }
+        },
+        "tag.Request": {
+            "type": "object",
+            "required": [
+                "tags"
+            ],
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "text.Response": {
             "type": "object",
             "properties": {
@@ -359,6 +3493,26 @@ const docTemplate = `{
                 }
             }
         },
+        "trash.ListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
         "up.Request": {
             "type": "object",
             "required": [
@@ -367,21 +3521,35 @@ const docTemplate = `{
             ],
             "properties": {
                 "group": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "link": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "release_date": {
                     "type": "string"
                 },
                 "song": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "text": {
                     "type": "string"
                 }
             }
+        },
+        "up.Response": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
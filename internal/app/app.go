@@ -1,22 +1,42 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	_ "song-lib/docs"
 	"song-lib/internal/config"
 	"song-lib/internal/database/postgres"
+	"song-lib/internal/enrichment"
 	"song-lib/internal/lib/logs"
 	"song-lib/internal/services"
 	"song-lib/internal/transport/rest/handlers/add"
+	"song-lib/internal/transport/rest/handlers/album"
+	"song-lib/internal/transport/rest/handlers/artist"
 	"song-lib/internal/transport/rest/handlers/del"
+	"song-lib/internal/transport/rest/handlers/find"
 	"song-lib/internal/transport/rest/handlers/get"
+	"song-lib/internal/transport/rest/handlers/patch"
+	"song-lib/internal/transport/rest/handlers/playlist"
+	"song-lib/internal/transport/rest/handlers/progress"
+	"song-lib/internal/transport/rest/handlers/refresh"
+	"song-lib/internal/transport/rest/handlers/revision"
+	"song-lib/internal/transport/rest/handlers/song"
+	"song-lib/internal/transport/rest/handlers/status"
+	"song-lib/internal/transport/rest/handlers/suggest"
+	"song-lib/internal/transport/rest/handlers/tag"
 	"song-lib/internal/transport/rest/handlers/text"
+	"song-lib/internal/transport/rest/handlers/trash"
 	"song-lib/internal/transport/rest/handlers/up"
+	"song-lib/internal/worker"
+	"syscall"
 )
 
 func Run() {
@@ -33,9 +53,43 @@ func Run() {
 	log.Info("Database connected")
 	log.Info("Migration is up")
 
+	providers, err := enrichment.NewProviders(log, cfg.Enrichment)
+	if err != nil {
+		log.Error("Failed to create enrichment providers", "error", err)
+		return
+	}
+	merger := enrichment.NewMerger(log, providers...)
+	log.Info("Enrichment providers created", slog.Int("count", len(providers)))
+
+	var enricher enrichment.Enricher = merger
+	switch cfg.Cache.Backend {
+	case "memory":
+		enricher = enrichment.NewCached(log, merger, enrichment.NewMemoryCache(cfg.Cache.Size), cfg.Cache)
+	case "postgres":
+		enricher = enrichment.NewCached(log, merger, db, cfg.Cache)
+	case "none", "":
+	default:
+		log.Error("Unknown cache backend", slog.String("backend", cfg.Cache.Backend))
+		return
+	}
+	log.Info("Enrichment cache configured", slog.String("backend", cfg.Cache.Backend))
+
 	src := services.New(db)
 	log.Info("Services created")
 
+	pool := worker.NewPool(log, enricher, src, cfg.Worker)
+	if err := pool.Start(); err != nil {
+		log.Error("Failed to start enrichment workers", "error", err)
+		return
+	}
+
+	// Ответ POST /songs/{id}/refresh должен успеть до таймаута записи, иначе клиент его не получит
+	refreshTimeout := cfg.Refresh.RequestTimeout
+	if cfg.Server.Timeout > 0 && refreshTimeout >= cfg.Server.Timeout {
+		refreshTimeout = cfg.Server.Timeout * 3 / 4
+		log.Warn("Refresh request timeout reduced below server timeout", slog.Duration("timeout", refreshTimeout))
+	}
+
 	router := chi.NewRouter()
 
 	// Middlewares
@@ -45,10 +99,56 @@ func Run() {
 	router.Use(middleware.URLFormat)
 
 	router.Get("/songs", get.New(log, src))
+	router.Get("/songs/enrichment", progress.New(log, src))
+	router.Get("/songs/search", find.New(log, src))
+	router.Get("/songs/{id}", song.New(log, src))
 	router.Get("/songs/{id}/text", text.New(log, src))
-	router.Post("/songs", add.New(log, src))
+	router.Post("/songs", add.New(log, src, pool))
 	router.Delete("/songs/{id}", del.New(log, src))
+	router.Post("/songs/{id}/restore", trash.NewRestore(log, src))
+	router.Get("/songs/{id}/revisions", revision.NewList(log, src))
+	router.Get("/songs/{id}/revisions/diff", revision.NewDiff(log, src))
+	router.Get("/songs/{id}/revisions/{rev}", revision.NewGet(log, src))
+	router.Post("/songs/{id}/revisions/{rev}/restore", revision.NewRestore(log, src))
 	router.Put("/songs/{id}", up.New(log, src))
+	router.Patch("/songs/{id}", patch.New(log, src))
+	router.Post("/songs/refresh", refresh.NewBulk(log, pool))
+	router.Post("/songs/{id}/refresh", refresh.New(log, src, pool, refreshTimeout))
+	router.Put("/songs/{id}/tags", tag.NewSet(log, src))
+	router.Get("/tags", tag.NewCloud(log, src))
+
+	router.Get("/trash", trash.NewList(log, src))
+
+	router.Get("/suggest", suggest.New(log, src, cfg.Suggest.MaxAge))
+
+	router.Get("/artists", artist.NewList(log, src))
+	router.Post("/artists", artist.NewCreate(log, src))
+	router.Get("/artists/{id}", artist.NewGet(log, src))
+	router.Put("/artists/{id}", artist.NewUpdate(log, src))
+	router.Delete("/artists/{id}", artist.NewDelete(log, src))
+	router.Get("/artists/{id}/songs", get.NewByArtist(log, src, src))
+
+	router.Get("/albums", album.NewList(log, src))
+	router.Post("/albums", album.NewCreate(log, src))
+	router.Get("/albums/{id}", album.NewGet(log, src))
+	router.Put("/albums/{id}", album.NewUpdate(log, src))
+	router.Delete("/albums/{id}", album.NewDelete(log, src))
+	router.Get("/albums/{id}/tracks", album.NewTracks(log, src))
+	router.Put("/albums/{id}/tracks/{song_id}", album.NewSetTrack(log, src))
+	router.Delete("/albums/{id}/tracks/{song_id}", album.NewRemoveTrack(log, src))
+
+	router.Get("/playlists", playlist.NewList(log, src))
+	router.Post("/playlists", playlist.NewCreate(log, src))
+	router.Get("/playlists/{id}", playlist.NewGet(log, src))
+	router.Put("/playlists/{id}", playlist.NewUpdate(log, src))
+	router.Delete("/playlists/{id}", playlist.NewDelete(log, src))
+	router.Get("/playlists/{id}/entries", playlist.NewEntries(log, src))
+	router.Post("/playlists/{id}/entries", playlist.NewAddEntry(log, src))
+	router.Put("/playlists/{id}/entries", playlist.NewReorder(log, src))
+	router.Patch("/playlists/{id}/entries/{entry_id}", playlist.NewMoveEntry(log, src))
+	router.Delete("/playlists/{id}/entries/{entry_id}", playlist.NewRemoveEntry(log, src))
+
+	router.Get("/enrichment/status", status.New(log, merger))
 
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 
@@ -63,9 +163,39 @@ func Run() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler := worker.NewScheduler(log, src, pool, cfg.Refresh)
+	go scheduler.Run(ctx)
+
+	purger := worker.NewPurger(log, src, cfg.Trash)
+	go purger.Run(ctx)
+
+	if cfg.Cache.Backend == "postgres" {
+		cleaner := worker.NewCacheCleaner(log, db, cfg.Cache)
+		go cleaner.Run(ctx)
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server", "error", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Worker.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to shutdown server", "error", err)
+	}
+	if err := pool.Stop(shutdownCtx); err != nil {
+		log.Error("failed to stop enrichment workers", "error", err)
 	}
 
-	log.Error("server stopped")
+	log.Info("server stopped")
 }
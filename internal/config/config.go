@@ -7,9 +7,15 @@ import (
 )
 
 type Config struct {
-	Database `yaml:"database"`
-	LogLevel string `env:"LOG_LEVEL"`
-	Server   `yaml:"server"`
+	Database   `yaml:"database"`
+	LogLevel   string `env:"LOG_LEVEL"`
+	Server     `yaml:"server"`
+	Enrichment `yaml:"enrichment"`
+	Worker     `yaml:"worker"`
+	Refresh    `yaml:"refresh"`
+	Cache      `yaml:"cache"`
+	Suggest    `yaml:"suggest"`
+	Trash      `yaml:"trash"`
 }
 
 type Database struct {
@@ -27,6 +33,66 @@ type Server struct {
 	IdleTimeout time.Duration `env:"SERVER_IDLE_TIMEOUT"`
 }
 
+// Enrichment описывает подключение к внешнему API с информацией о песнях
+type Enrichment struct {
+	BaseURL    string        `env:"ENRICHMENT_BASE_URL" env-default:"http://localhost:8081"`
+	Timeout    time.Duration `env:"ENRICHMENT_TIMEOUT" env-default:"5s"`
+	AuthHeader string        `env:"ENRICHMENT_AUTH_HEADER" env-default:"Authorization"`
+	AuthToken  string        `env:"ENRICHMENT_AUTH_TOKEN"`
+	// Providers - упорядоченный список источников вида name=url. Если пуст, используется BaseURL
+	Providers []string `env:"ENRICHMENT_PROVIDERS" env-separator:","`
+
+	RetryAttempts  int           `env:"ENRICHMENT_RETRY_ATTEMPTS" env-default:"3"`
+	RetryBaseDelay time.Duration `env:"ENRICHMENT_RETRY_BASE_DELAY" env-default:"200ms"`
+	RetryMaxDelay  time.Duration `env:"ENRICHMENT_RETRY_MAX_DELAY" env-default:"2s"`
+
+	BreakerThreshold int           `env:"ENRICHMENT_BREAKER_THRESHOLD" env-default:"5"`
+	BreakerCooldown  time.Duration `env:"ENRICHMENT_BREAKER_COOLDOWN" env-default:"30s"`
+}
+
+// Worker описывает пул фоновых обработчиков, обогащающих песни. Раз в ResumeInterval
+// в очередь ставятся песни pending, не поместившиеся в неё раньше
+type Worker struct {
+	Count           int           `env:"WORKER_COUNT" env-default:"4"`
+	QueueSize       int           `env:"WORKER_QUEUE_SIZE" env-default:"100"`
+	JobTimeout      time.Duration `env:"WORKER_JOB_TIMEOUT" env-default:"30s"`
+	ShutdownTimeout time.Duration `env:"WORKER_SHUTDOWN_TIMEOUT" env-default:"10s"`
+	ResumeInterval  time.Duration `env:"WORKER_RESUME_INTERVAL" env-default:"1m"`
+}
+
+// Refresh описывает периодическое повторное обогащение устаревших песен. RequestTimeout -
+// сколько POST /songs/{id}/refresh ждёт внешнее API, он должен быть меньше таймаута записи сервера
+type Refresh struct {
+	Interval       time.Duration `env:"REFRESH_INTERVAL" env-default:"1h"`
+	MaxAge         time.Duration `env:"REFRESH_MAX_AGE" env-default:"720h"`
+	BatchSize      int           `env:"REFRESH_BATCH_SIZE" env-default:"50"`
+	RequestTimeout time.Duration `env:"REFRESH_REQUEST_TIMEOUT" env-default:"3s"`
+}
+
+// Cache описывает кэш ответов внешнего API. Backend: memory, postgres или none.
+// Устаревшие записи postgres удаляются раз в PurgeInterval порциями по PurgeBatchSize
+type Cache struct {
+	Backend        string        `env:"CACHE_BACKEND" env-default:"memory"`
+	Size           int           `env:"CACHE_SIZE" env-default:"1000"`
+	TTL            time.Duration `env:"CACHE_TTL" env-default:"24h"`
+	NegativeTTL    time.Duration `env:"CACHE_NEGATIVE_TTL" env-default:"1h"`
+	PurgeInterval  time.Duration `env:"CACHE_PURGE_INTERVAL" env-default:"1h"`
+	PurgeBatchSize int           `env:"CACHE_PURGE_BATCH_SIZE" env-default:"500"`
+}
+
+// Suggest описывает автодополнение. MaxAge - сколько клиент и прокси могут кэшировать подсказки
+type Suggest struct {
+	MaxAge time.Duration `env:"SUGGEST_MAX_AGE" env-default:"60s"`
+}
+
+// Trash описывает очистку корзины: песни, удалённые раньше Retention, удаляются навсегда
+// раз в PurgeInterval порциями по BatchSize. Нулевой PurgeInterval отключает очистку
+type Trash struct {
+	Retention     time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
+	PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+	BatchSize     int           `env:"TRASH_PURGE_BATCH_SIZE" env-default:"500"`
+}
+
 // MustLoad загружает конфигурацию из файла и переменных окружения
 func MustLoad() *Config {
 	var cfg Config
@@ -0,0 +1,332 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"strings"
+)
+
+var (
+	// ErrAlbumNotFound - альбома с таким ID нет
+	ErrAlbumNotFound error = errs.New(errs.ErrNotFound, "album not found")
+	// ErrTrackNotFound - песни нет в альбоме
+	ErrTrackNotFound error = errs.New(errs.ErrNotFound, "song is not in the album")
+	// ErrTrackArtistMismatch - песня и альбом принадлежат разным исполнителям
+	ErrTrackArtistMismatch error = errs.New(errs.ErrValidation, "song and album belong to different artists")
+	// ErrAlbumHasTracks - исполнителя альбома нельзя сменить, пока в альбоме есть треки
+	ErrAlbumHasTracks error = errs.New(errs.ErrConflict, "cannot change the artist of an album with tracks")
+)
+
+// albumColumns - колонки альбома, имя исполнителя и число треков.
+// Таблица albums должна называться al, artists - ar
+const albumColumns = "al.id, al.artist_id, ar.name, al.title, al.release_date, al.cover, (SELECT COUNT(*) FROM songs s WHERE s.album_id = al.id AND s.deleted_at IS NULL)"
+
+const albumsFrom = " FROM albums al JOIN artists ar ON ar.id = al.artist_id"
+
+// trackOrder - порядок треков альбома: по дискам, внутри диска по номеру, треки без номера в конце
+const trackOrder = "disc_number, track_number NULLS LAST, id"
+
+func scanAlbum(row rowScanner) (models.Album, error) {
+	var album models.Album
+	err := row.Scan(&album.ID, &album.ArtistID, &album.Artist, &album.Title, &album.ReleaseDate, &album.Cover, &album.TrackCount)
+	return album, err
+}
+
+// GetAlbums возвращает страницу альбомов по названию и признак того, что есть следующая страница.
+// artistID - только альбомы исполнителя (0 - все), title - подстрока названия без учёта регистра
+func (d *Database) GetAlbums(artistID int64, title string, page, limit int) ([]models.Album, bool, error) {
+	const op = "internal.database.postgres.GetAlbums"
+
+	var (
+		args       []any
+		conditions []string
+	)
+	arg := placeholders(&args)
+
+	if artistID != 0 {
+		conditions = append(conditions, "al.artist_id = "+arg(artistID))
+	}
+	if title != "" {
+		conditions = append(conditions, "al.title ILIKE "+arg(containsPattern(title)))
+	}
+
+	query := "SELECT " + albumColumns + albumsFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY al.title, al.id LIMIT " + arg(limit+1) + " OFFSET " + arg((page-1)*limit)
+
+	rows, err := d.Db.Query(query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var albums []models.Album
+	for rows.Next() {
+		album, err := scanAlbum(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if len(albums) > limit {
+		return albums[:limit], true, nil
+	}
+	return albums, false, nil
+}
+
+func (d *Database) GetAlbum(id int64) (*models.Album, error) {
+	const op = "internal.database.postgres.GetAlbum"
+	query := "SELECT " + albumColumns + albumsFrom + " WHERE al.id = $1"
+
+	album, err := scanAlbum(d.Db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrAlbumNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, err)
+	}
+	return &album, nil
+}
+
+func (d *Database) AddAlbum(album *models.Album) (int64, error) {
+	const op = "internal.database.postgres.AddAlbum"
+	query := "INSERT INTO albums (artist_id, title, release_date, cover) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	var id int64
+	err := d.Db.QueryRow(query, album.ArtistID, album.Title, album.ReleaseDate, album.Cover).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: query row: %w", op, classify(err))
+	}
+	return id, nil
+}
+
+// UpdateAlbum изменяет альбом. Исполнителя можно сменить только у альбома без треков,
+// включая песни в корзине
+func (d *Database) UpdateAlbum(album *models.Album) (int64, error) {
+	const op = "internal.database.postgres.UpdateAlbum"
+	query := "UPDATE albums SET artist_id = $1, title = $2, release_date = $3, cover = $4 WHERE id = $5"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	artistID, err := lockAlbum(tx, album.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if artistID != album.ArtistID {
+		var hasTracks bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM songs WHERE album_id = $1)", album.ID).Scan(&hasTracks); err != nil {
+			return 0, fmt.Errorf("%s: check tracks %w", op, err)
+		}
+		if hasTracks {
+			return 0, fmt.Errorf("%s: %w", op, ErrAlbumHasTracks)
+		}
+	}
+
+	result, err := tx.Exec(query, album.ArtistID, album.Title, album.ReleaseDate, album.Cover, album.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// lockAlbum блокирует строку альбома до конца транзакции и возвращает его исполнителя.
+// Все изменения треков альбома сначала берут эту блокировку, поэтому проверки номера трека
+// и исполнителя не гоняются друг с другом
+func lockAlbum(tx *sql.Tx, id int64) (int64, error) {
+	var artistID int64
+	if err := tx.QueryRow("SELECT artist_id FROM albums WHERE id = $1 FOR UPDATE", id).Scan(&artistID); err != nil {
+		return 0, fmt.Errorf("lock album: %w", err)
+	}
+	return artistID, nil
+}
+
+// DeleteAlbum удаляет альбом. Песни альбома остаются в библиотеке без альбома и номеров
+func (d *Database) DeleteAlbum(id int64) (int64, error) {
+	const op = "internal.database.postgres.DeleteAlbum"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL WHERE album_id = $1", id); err != nil {
+		return 0, fmt.Errorf("%s: detach songs %w", op, err)
+	}
+
+	result, err := tx.Exec("DELETE FROM albums WHERE id = $1", id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// GetAlbumTracks возвращает песни альбома в порядке треков
+func (d *Database) GetAlbumTracks(id int64) ([]models.Song, error) {
+	const op = "internal.database.postgres.GetAlbumTracks"
+	query := "SELECT " + songColumns + " FROM songs WHERE album_id = $1 AND deleted_at IS NULL ORDER BY " + trackOrder
+
+	rows, err := d.Db.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	songs, err := scanSongs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return songs, nil
+}
+
+// manualAlbum отмечает, что альбом песни выбран пользователем: обогащение его больше не меняет
+const manualAlbum = `provenance = COALESCE(provenance, '{}'::jsonb) || jsonb_build_object('` + models.FieldAlbum + `', '` + models.ProvenanceManual + `')`
+
+// SetAlbumTrack помещает песню в альбом или переносит её на другое место. Песня должна
+// принадлежать исполнителю альбома. Занятый номер трека на том же диске возвращает конфликт
+func (d *Database) SetAlbumTrack(albumID, songID int64, track models.Track) (int64, error) {
+	const op = "internal.database.postgres.SetAlbumTrack"
+	query := "UPDATE songs SET album_id = $1, disc_number = $2, track_number = $3, " + manualAlbum + " WHERE id = $4 AND deleted_at IS NULL"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	artistID, err := lockAlbum(tx, albumID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrAlbumNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var songArtistID int64
+	err = tx.QueryRow("SELECT artist_id FROM songs WHERE id = $1 AND deleted_at IS NULL", songID).Scan(&songArtistID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s: get song artist %w", op, err)
+	}
+	if songArtistID != artistID {
+		return 0, fmt.Errorf("%s: %w", op, ErrTrackArtistMismatch)
+	}
+
+	result, err := tx.Exec(query, albumID, track.DiscNumber, track.TrackNumber, songID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// RemoveAlbumTrack убирает песню из альбома, если она в нём есть
+func (d *Database) RemoveAlbumTrack(albumID, songID int64) (int64, error) {
+	const op = "internal.database.postgres.RemoveAlbumTrack"
+	query := "UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL, " + manualAlbum + " WHERE id = $1 AND album_id = $2 AND deleted_at IS NULL"
+
+	result, err := d.Db.Exec(query, songID, albumID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// attachAlbum помещает песню в альбом из внешнего API, создавая альбом у исполнителя песни.
+// Песни, которые уже в альбоме или альбом которых выбран пользователем, не меняются.
+// Занятый номер трека не сохраняется, проверка идёт под блокировкой альбома (см. lockAlbum).
+// Возвращает, была ли песня помещена в альбом
+func attachAlbum(tx *sql.Tx, songID int64, album *models.AlbumDetails) (bool, error) {
+	var albumID int64
+	err := tx.QueryRow(`
+		INSERT INTO albums (artist_id, title, release_date, cover)
+		SELECT artist_id, $2, $3, $4 FROM songs
+		WHERE id = $1 AND deleted_at IS NULL AND album_id IS NULL AND (provenance->>'`+models.FieldAlbum+`') IS DISTINCT FROM '`+models.ProvenanceManual+`'
+		ON CONFLICT (artist_id, title) DO UPDATE SET
+			release_date = CASE WHEN albums.release_date = '' THEN EXCLUDED.release_date ELSE albums.release_date END,
+			cover = CASE WHEN albums.cover = '' THEN EXCLUDED.cover ELSE albums.cover END
+		RETURNING id`,
+		songID, album.Title, album.ReleaseDate, album.Cover,
+	).Scan(&albumID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("upsert album: %w", classify(err))
+	}
+	if _, err := lockAlbum(tx, albumID); err != nil {
+		return false, err
+	}
+
+	disc := album.DiscNumber
+	if disc <= 0 {
+		disc = 1
+	}
+	var track *int
+	if album.TrackNumber > 0 {
+		track = &album.TrackNumber
+	}
+
+	_, err = tx.Exec(`
+		UPDATE songs SET album_id = $2, disc_number = $3,
+			track_number = CASE WHEN EXISTS (
+				SELECT 1 FROM songs t WHERE t.album_id = $2 AND t.disc_number = $3 AND t.track_number = $4 AND t.deleted_at IS NULL
+			) THEN NULL ELSE $4 END
+		WHERE id = $1`,
+		songID, albumID, disc, track,
+	)
+	if err != nil {
+		return false, fmt.Errorf("attach song: %w", classify(err))
+	}
+	return true, nil
+}
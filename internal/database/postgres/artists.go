@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+)
+
+var (
+	// ErrArtistNotFound - исполнителя с таким ID нет
+	ErrArtistNotFound error = errs.New(errs.ErrNotFound, "artist not found")
+	// ErrArtistHasTrashedSongs - удалению исполнителя мешают только его песни в корзине
+	ErrArtistHasTrashedSongs error = errs.New(errs.ErrConflict, "artist still has songs in the trash, restore or purge them first")
+)
+
+// artistColumns - колонки исполнителя и число его песен, таблица artists должна называться a
+const artistColumns = "a.id, a.name, (SELECT COUNT(*) FROM songs s WHERE s.artist_id = a.id AND s.deleted_at IS NULL)"
+
+func scanArtist(row rowScanner) (models.Artist, error) {
+	var artist models.Artist
+	err := row.Scan(&artist.ID, &artist.Name, &artist.SongCount)
+	return artist, err
+}
+
+// GetArtists возвращает страницу исполнителей по алфавиту и признак того, что есть следующая страница.
+// name - подстрока имени без учёта регистра
+func (d *Database) GetArtists(name string, page, limit int) ([]models.Artist, bool, error) {
+	const op = "internal.database.postgres.GetArtists"
+
+	var args []any
+	arg := placeholders(&args)
+
+	query := "SELECT " + artistColumns + " FROM artists a"
+	if name != "" {
+		query += " WHERE a.name ILIKE " + arg(containsPattern(name))
+	}
+	query += " ORDER BY a.name, a.id LIMIT " + arg(limit+1) + " OFFSET " + arg((page-1)*limit)
+
+	rows, err := d.Db.Query(query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var artists []models.Artist
+	for rows.Next() {
+		artist, err := scanArtist(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		artists = append(artists, artist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if len(artists) > limit {
+		return artists[:limit], true, nil
+	}
+	return artists, false, nil
+}
+
+func (d *Database) GetArtist(id int64) (*models.Artist, error) {
+	const op = "internal.database.postgres.GetArtist"
+	query := "SELECT " + artistColumns + " FROM artists a WHERE a.id = $1"
+
+	artist, err := scanArtist(d.Db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrArtistNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, err)
+	}
+	return &artist, nil
+}
+
+func (d *Database) AddArtist(artist *models.Artist) (int64, error) {
+	const op = "internal.database.postgres.AddArtist"
+	query := "INSERT INTO artists (name) VALUES ($1) RETURNING id"
+
+	var id int64
+	if err := d.Db.QueryRow(query, artist.Name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s: query row: %w", op, classify(err))
+	}
+	return id, nil
+}
+
+// UpdateArtist переименовывает исполнителя, триггер artists_sync_songs обновляет группу его песен
+func (d *Database) UpdateArtist(artist *models.Artist) (int64, error) {
+	const op = "internal.database.postgres.UpdateArtist"
+	query := "UPDATE artists SET name = $1 WHERE id = $2"
+
+	result, err := d.Db.Exec(query, artist.Name, artist.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// DeleteArtist удаляет исполнителя без песен. Если песни есть, внешний ключ возвращает конфликт
+func (d *Database) DeleteArtist(id int64) (int64, error) {
+	const op = "internal.database.postgres.DeleteArtist"
+	query := "DELETE FROM artists WHERE id = $1"
+
+	result, err := d.Db.Exec(query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+			trashedOnly, checkErr := d.blockedByTrash(id)
+			if checkErr != nil {
+				return 0, fmt.Errorf("%s: %w", op, checkErr)
+			}
+			if trashedOnly {
+				return 0, fmt.Errorf("%s: %w", op, ErrArtistHasTrashedSongs)
+			}
+		}
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// blockedByTrash проверяет, что удалению исполнителя мешают только его песни в корзине
+func (d *Database) blockedByTrash(artistID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM songs WHERE artist_id = $1 AND deleted_at IS NOT NULL)
+		AND NOT EXISTS (SELECT 1 FROM songs WHERE artist_id = $1 AND deleted_at IS NULL)
+		AND NOT EXISTS (SELECT 1 FROM albums WHERE artist_id = $1)`
+
+	var trashedOnly bool
+	if err := d.Db.QueryRow(query, artistID).Scan(&trashedOnly); err != nil {
+		return false, fmt.Errorf("check trashed songs: %w", err)
+	}
+	return trashedOnly, nil
+}
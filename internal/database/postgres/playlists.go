@@ -0,0 +1,501 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"slices"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+)
+
+var (
+	// ErrPlaylistNotFound - плейлиста с таким ID нет
+	ErrPlaylistNotFound error = errs.New(errs.ErrNotFound, "playlist not found")
+	// ErrEntryNotFound - в плейлисте нет записи с таким ID
+	ErrEntryNotFound error = errs.New(errs.ErrNotFound, "playlist entry not found")
+	// ErrDuplicateEntry - песня уже есть в плейлисте без повторов
+	ErrDuplicateEntry error = errs.New(errs.ErrConflict, "song is already in the playlist")
+	// ErrPlaylistHasDuplicates - повторы нельзя запретить, пока они есть в плейлисте
+	ErrPlaylistHasDuplicates error = errs.New(errs.ErrConflict, "playlist has duplicate songs")
+	// ErrEntriesMismatch - новый порядок не совпадает с текущими записями плейлиста
+	ErrEntriesMismatch error = errs.New(errs.ErrConflict, "entry_ids must list every entry of the playlist exactly once")
+)
+
+// playlistColumns - колонки плейлиста и число записей, таблица playlists должна называться p
+const playlistColumns = "p.id, p.name, p.description, p.allow_duplicates, " +
+	"(SELECT COUNT(*) FROM playlist_entries e JOIN songs s ON s.id = e.song_id WHERE e.playlist_id = p.id AND s.deleted_at IS NULL), " +
+	"p.created_at, p.updated_at"
+
+// playlistEntries - записи плейлиста $1 с песнями не из корзины. Порядковый номер считается
+// по позициям видимых записей, поэтому записи песен в корзине не оставляют пропусков
+const playlistEntries = `
+	WITH entries AS (
+		SELECT e.id AS entry_id, e.song_id, e.added_at, ROW_NUMBER() OVER (ORDER BY e.position, e.id) AS entry_position
+		FROM playlist_entries e JOIN songs s ON s.id = e.song_id
+		WHERE e.playlist_id = $1 AND s.deleted_at IS NULL
+	)
+	SELECT ` + songColumns + `, entries.entry_id, entries.entry_position, entries.added_at
+	FROM entries JOIN songs ON songs.id = entries.song_id`
+
+func scanPlaylist(row rowScanner) (models.Playlist, error) {
+	var playlist models.Playlist
+	err := row.Scan(&playlist.ID, &playlist.Name, &playlist.Description, &playlist.AllowDuplicates, &playlist.EntryCount, &playlist.CreatedAt, &playlist.UpdatedAt)
+	return playlist, err
+}
+
+func scanEntry(row rowScanner) (models.PlaylistEntry, error) {
+	var entry models.PlaylistEntry
+	song, err := scanSong(row, &entry.ID, &entry.Position, &entry.AddedAt)
+	entry.Song = song
+	return entry, err
+}
+
+// GetPlaylists возвращает страницу плейлистов, новые первыми, и признак того, что есть следующая страница
+func (d *Database) GetPlaylists(page, limit int) ([]models.Playlist, bool, error) {
+	const op = "internal.database.postgres.GetPlaylists"
+	query := "SELECT " + playlistColumns + " FROM playlists p ORDER BY p.id DESC LIMIT $1 OFFSET $2"
+
+	rows, err := d.Db.Query(query, limit+1, (page-1)*limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var playlists []models.Playlist
+	for rows.Next() {
+		playlist, err := scanPlaylist(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		playlists = append(playlists, playlist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if len(playlists) > limit {
+		return playlists[:limit], true, nil
+	}
+	return playlists, false, nil
+}
+
+func (d *Database) GetPlaylist(id int64) (*models.Playlist, error) {
+	const op = "internal.database.postgres.GetPlaylist"
+	query := "SELECT " + playlistColumns + " FROM playlists p WHERE p.id = $1"
+
+	playlist, err := scanPlaylist(d.Db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrPlaylistNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, err)
+	}
+	return &playlist, nil
+}
+
+func (d *Database) AddPlaylist(playlist *models.Playlist) (int64, error) {
+	const op = "internal.database.postgres.AddPlaylist"
+	query := "INSERT INTO playlists (name, description, allow_duplicates) VALUES ($1, $2, $3) RETURNING id"
+
+	var id int64
+	err := d.Db.QueryRow(query, playlist.Name, playlist.Description, playlist.AllowDuplicates).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: query row: %w", op, classify(err))
+	}
+	return id, nil
+}
+
+// UpdatePlaylist меняет название, описание и разрешение повторов.
+// Запретить повторы можно только плейлисту, в котором их нет
+func (d *Database) UpdatePlaylist(playlist *models.Playlist) error {
+	const op = "internal.database.postgres.UpdatePlaylist"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockPlaylist(tx, playlist.ID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !playlist.AllowDuplicates {
+		var duplicates bool
+		err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM playlist_entries WHERE playlist_id = $1 GROUP BY song_id HAVING COUNT(*) > 1)", playlist.ID).Scan(&duplicates)
+		if err != nil {
+			return fmt.Errorf("%s: check duplicates %w", op, err)
+		}
+		if duplicates {
+			return fmt.Errorf("%s: %w", op, ErrPlaylistHasDuplicates)
+		}
+	}
+
+	query := "UPDATE playlists SET name = $1, description = $2, allow_duplicates = $3, updated_at = NOW() WHERE id = $4"
+	if _, err := tx.Exec(query, playlist.Name, playlist.Description, playlist.AllowDuplicates, playlist.ID); err != nil {
+		return fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit %w", op, err)
+	}
+	return nil
+}
+
+// DeletePlaylist удаляет плейлист вместе с записями, песни остаются в библиотеке
+func (d *Database) DeletePlaylist(id int64) (int64, error) {
+	const op = "internal.database.postgres.DeletePlaylist"
+	query := "DELETE FROM playlists WHERE id = $1"
+
+	result, err := d.Db.Exec(query, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+// GetPlaylistEntries возвращает страницу записей по порядку и признак того, что есть следующая страница
+func (d *Database) GetPlaylistEntries(id int64, page, limit int) ([]models.PlaylistEntry, bool, error) {
+	const op = "internal.database.postgres.GetPlaylistEntries"
+	query := playlistEntries + " ORDER BY entries.entry_position LIMIT $2 OFFSET $3"
+
+	rows, err := d.Db.Query(query, id, limit+1, (page-1)*limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []models.PlaylistEntry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if len(entries) > limit {
+		return entries[:limit], true, nil
+	}
+	return entries, false, nil
+}
+
+// AddPlaylistEntry вставляет песню на позицию position, записи с этой позиции сдвигаются вниз.
+// Позиция 0 или больше длины плейлиста добавляет песню в конец
+func (d *Database) AddPlaylistEntry(playlistID, songID int64, position int) (*models.PlaylistEntry, error) {
+	const op = "internal.database.postgres.AddPlaylistEntry"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	allowDuplicates, err := lockPlaylist(tx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var exists, duplicate bool
+	err = tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM songs WHERE id = $2 AND "+notDeleted+"), EXISTS (SELECT 1 FROM playlist_entries WHERE playlist_id = $1 AND song_id = $2)",
+		playlistID, songID,
+	).Scan(&exists, &duplicate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: check song %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, ErrSongNotFound)
+	}
+	if duplicate && !allowDuplicates {
+		return nil, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
+	}
+
+	var entryID int64
+	err = tx.QueryRow(`
+		INSERT INTO playlist_entries (playlist_id, song_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM playlist_entries WHERE playlist_id = $1
+		RETURNING id`,
+		playlistID, songID,
+	).Scan(&entryID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: insert entry %w", op, classify(err))
+	}
+
+	slots, err := loadOrder(tx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	slots, entry := takeSlot(slots, entryID)
+	if err := saveOrder(tx, playlistID, insertSlot(slots, entry, position)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	added, err := finishEntryChange(tx, playlistID, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return added, nil
+}
+
+// MovePlaylistEntry переносит запись на позицию position, записи между старой и новой позицией
+// сдвигаются. Позиция больше длины плейлиста переносит запись в конец
+func (d *Database) MovePlaylistEntry(playlistID, entryID int64, position int) (*models.PlaylistEntry, error) {
+	const op = "internal.database.postgres.MovePlaylistEntry"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockPlaylist(tx, playlistID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slots, err := loadOrder(tx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	slots, entry := takeSlot(slots, entryID)
+	if entry.id == 0 || entry.hidden {
+		return nil, fmt.Errorf("%s: %w", op, ErrEntryNotFound)
+	}
+	if err := saveOrder(tx, playlistID, insertSlot(slots, entry, max(position, 1))); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	moved, err := finishEntryChange(tx, playlistID, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return moved, nil
+}
+
+// RemovePlaylistEntry удаляет запись, следующие записи поднимаются на её место
+func (d *Database) RemovePlaylistEntry(playlistID, entryID int64) error {
+	const op = "internal.database.postgres.RemovePlaylistEntry"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockPlaylist(tx, playlistID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	slots, err := loadOrder(tx, playlistID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	slots, entry := takeSlot(slots, entryID)
+	if entry.id == 0 || entry.hidden {
+		return fmt.Errorf("%s: %w", op, ErrEntryNotFound)
+	}
+
+	if _, err := tx.Exec("DELETE FROM playlist_entries WHERE id = $1", entryID); err != nil {
+		return fmt.Errorf("%s: exec %w", op, err)
+	}
+	if err := saveOrder(tx, playlistID, slots); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := touchPlaylist(tx, playlistID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit %w", op, err)
+	}
+	return nil
+}
+
+// ReorderPlaylist задаёт новый порядок записей. entryIDs должен содержать каждую запись плейлиста
+// ровно один раз, иначе порядок был составлен по устаревшему списку и возвращается конфликт.
+// Скрытые записи песен из корзины остаются на своих местах
+func (d *Database) ReorderPlaylist(playlistID int64, entryIDs []int64) error {
+	const op = "internal.database.postgres.ReorderPlaylist"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockPlaylist(tx, playlistID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	slots, err := loadOrder(tx, playlistID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	visible := make(map[int64]bool, len(slots))
+	for _, slot := range slots {
+		if !slot.hidden {
+			visible[slot.id] = true
+		}
+	}
+	if len(entryIDs) != len(visible) {
+		return fmt.Errorf("%s: %w", op, ErrEntriesMismatch)
+	}
+	requested := make(map[int64]bool, len(entryIDs))
+	for _, id := range entryIDs {
+		if !visible[id] || requested[id] {
+			return fmt.Errorf("%s: %w", op, ErrEntriesMismatch)
+		}
+		requested[id] = true
+	}
+
+	next := 0
+	for i, slot := range slots {
+		if slot.hidden {
+			continue
+		}
+		slots[i] = playlistSlot{id: entryIDs[next]}
+		next++
+	}
+
+	if err := saveOrder(tx, playlistID, slots); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := touchPlaylist(tx, playlistID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit %w", op, err)
+	}
+	return nil
+}
+
+// lockPlaylist блокирует строку плейлиста до конца транзакции: изменения одного плейлиста
+// выполняются по очереди, поэтому позиции не перемешиваются. Возвращает разрешение повторов
+func lockPlaylist(tx *sql.Tx, id int64) (bool, error) {
+	var allowDuplicates bool
+	err := tx.QueryRow("SELECT allow_duplicates FROM playlists WHERE id = $1 FOR UPDATE", id).Scan(&allowDuplicates)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, ErrPlaylistNotFound
+		}
+		return false, fmt.Errorf("lock playlist: %w", err)
+	}
+	return allowDuplicates, nil
+}
+
+// playlistSlot - запись плейлиста в общем порядке. hidden - песня записи в корзине:
+// клиент запись не видит и позиции считает без неё, но место записи сохраняется до восстановления песни
+type playlistSlot struct {
+	id     int64
+	hidden bool
+}
+
+// loadOrder возвращает все записи плейлиста по порядку. Плейлист должен быть заблокирован
+func loadOrder(tx *sql.Tx, id int64) ([]playlistSlot, error) {
+	rows, err := tx.Query(`
+		SELECT e.id, s.deleted_at IS NOT NULL
+		FROM playlist_entries e JOIN songs s ON s.id = e.song_id
+		WHERE e.playlist_id = $1
+		ORDER BY e.position, e.id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("load order: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []playlistSlot
+	for rows.Next() {
+		var slot playlistSlot
+		if err := rows.Scan(&slot.id, &slot.hidden); err != nil {
+			return nil, fmt.Errorf("load order scan: %w", err)
+		}
+		slots = append(slots, slot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load order rows: %w", err)
+	}
+	return slots, nil
+}
+
+// saveOrder записывает позиции 1..n в порядке slots. Совпадения позиций в процессе
+// допустимы: уникальность проверяется в конце транзакции
+func saveOrder(tx *sql.Tx, playlistID int64, slots []playlistSlot) error {
+	ids := make([]int64, len(slots))
+	for i, slot := range slots {
+		ids[i] = slot.id
+	}
+
+	query := `UPDATE playlist_entries e SET position = o.number
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, number)
+		WHERE e.id = o.id AND e.playlist_id = $1 AND e.position <> o.number`
+	if _, err := tx.Exec(query, playlistID, pq.Array(ids)); err != nil {
+		return fmt.Errorf("save order: %w", err)
+	}
+	return nil
+}
+
+// takeSlot убирает запись из порядка. Если записи нет, возвращается нулевой playlistSlot
+func takeSlot(slots []playlistSlot, id int64) ([]playlistSlot, playlistSlot) {
+	for i, slot := range slots {
+		if slot.id == id {
+			return append(slots[:i], slots[i+1:]...), slot
+		}
+	}
+	return slots, playlistSlot{}
+}
+
+// insertSlot ставит запись перед position-й видимой записью, то есть на видимую позицию position.
+// Позиция 0 или больше числа видимых записей ставит запись в конец
+func insertSlot(slots []playlistSlot, slot playlistSlot, position int) []playlistSlot {
+	index := len(slots)
+	if position > 0 {
+		seen := 0
+		for i, s := range slots {
+			if s.hidden {
+				continue
+			}
+			seen++
+			if seen == position {
+				index = i
+				break
+			}
+		}
+	}
+	return slices.Insert(slots, index, slot)
+}
+
+func touchPlaylist(tx *sql.Tx, id int64) error {
+	if _, err := tx.Exec("UPDATE playlists SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return fmt.Errorf("touch playlist: %w", err)
+	}
+	return nil
+}
+
+// finishEntryChange отмечает изменение плейлиста, фиксирует транзакцию и возвращает запись
+// с её новым порядковым номером
+func finishEntryChange(tx *sql.Tx, playlistID, entryID int64) (*models.PlaylistEntry, error) {
+	if err := touchPlaylist(tx, playlistID); err != nil {
+		return nil, err
+	}
+
+	entry, err := scanEntry(tx.QueryRow(playlistEntries+" WHERE entries.entry_id = $2", playlistID, entryID))
+	if err != nil {
+		return nil, fmt.Errorf("get entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return &entry, nil
+}
@@ -2,20 +2,152 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
-	_ "github.com/lib/pq" // init postgres driver
+	"github.com/lib/pq" // init postgres driver
 	"github.com/pressly/goose/v3"
+	"maps"
+	"slices"
 	"song-lib/internal/config"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/validation"
 	"song-lib/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+var (
+	// ErrSongNotFound - песни с таким ID нет или она в корзине
+	ErrSongNotFound error = errs.New(errs.ErrNotFound, "song not found")
+	// ErrSongNotInTrash - в корзине нет песни с таким ID
+	ErrSongNotInTrash error = errs.New(errs.ErrNotFound, "song not found in trash")
+)
+
+// Коды ошибок PostgreSQL, которые означают некорректные данные или конфликт, а не сбой
+const (
+	pqStringTooLong       = "22001"
+	pqInvalidText         = "22P02"
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+)
+
+// classify превращает ошибки нарушения ограничений в ошибки сервиса
+func classify(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pqStringTooLong, pqInvalidText, pqCheckViolation:
+		return errs.Wrap(errs.ErrValidation, "invalid value", err)
+	case pqUniqueViolation:
+		return errs.Wrap(errs.ErrConflict, "already exists", err)
+	case pqForeignKeyViolation:
+		return errs.Wrap(errs.ErrConflict, "referenced record does not exist or is still in use", err)
+	default:
+		return err
+	}
+}
+
 type DBSonger interface {
-	GetSongs(group, name string, page, limit int) ([]models.Song, error)
+	GetSongs(q models.SongQuery) (*models.SongList, error)
+	GetSong(id int64) (*models.Song, error)
 	AddSong(song *models.Song) (int64, error)
 	DeleteSong(id int64) (int64, error)
-	UpdateSong(song *models.Song) (int64, error)
+	UpdateSong(song *models.Song, author string) (int64, error)
+	PatchSong(id int64, patch models.SongPatch, author string) (*models.Song, error)
 	GetSongText(id int64) (*models.Song, error)
+	SearchSongs(q string, fuzzy bool, page, limit int) ([]models.SongSearchResult, bool, error)
+	SuggestSongs(field, prefix string, limit int) ([]models.Suggestion, error)
+	GetSongsByStatus(status string, page, limit int) ([]models.Song, error)
+	UpdateSongDetails(id int64, details *models.SongDetails) (int64, error)
+	SetEnrichmentStatus(id int64, status string) (int64, error)
+	SetEnrichmentFailed(id int64, permanent bool) (int64, error)
+	GetStaleSongs(before time.Time, limit int) ([]models.Song, error)
+	GetCachedDetails(key string) (*models.CachedSongDetails, error)
+	SetCachedDetails(key string, entry *models.CachedSongDetails) error
+	PurgeCachedDetails(before time.Time, limit int) (int64, error)
+	GetArtists(name string, page, limit int) ([]models.Artist, bool, error)
+	GetArtist(id int64) (*models.Artist, error)
+	AddArtist(artist *models.Artist) (int64, error)
+	UpdateArtist(artist *models.Artist) (int64, error)
+	DeleteArtist(id int64) (int64, error)
+	GetAlbums(artistID int64, title string, page, limit int) ([]models.Album, bool, error)
+	GetAlbum(id int64) (*models.Album, error)
+	AddAlbum(album *models.Album) (int64, error)
+	UpdateAlbum(album *models.Album) (int64, error)
+	DeleteAlbum(id int64) (int64, error)
+	GetAlbumTracks(id int64) ([]models.Song, error)
+	SetAlbumTrack(albumID, songID int64, track models.Track) (int64, error)
+	RemoveAlbumTrack(albumID, songID int64) (int64, error)
+	SetSongTags(id int64, tags []string) (*models.Song, error)
+	GetTagCloud(limit int) ([]models.TagCount, error)
+	GetPlaylists(page, limit int) ([]models.Playlist, bool, error)
+	GetPlaylist(id int64) (*models.Playlist, error)
+	AddPlaylist(playlist *models.Playlist) (int64, error)
+	UpdatePlaylist(playlist *models.Playlist) error
+	DeletePlaylist(id int64) (int64, error)
+	GetPlaylistEntries(id int64, page, limit int) ([]models.PlaylistEntry, bool, error)
+	AddPlaylistEntry(playlistID, songID int64, position int) (*models.PlaylistEntry, error)
+	MovePlaylistEntry(playlistID, entryID int64, position int) (*models.PlaylistEntry, error)
+	RemovePlaylistEntry(playlistID, entryID int64) error
+	ReorderPlaylist(playlistID int64, entryIDs []int64) error
+	GetTrash(page, limit int) ([]models.Song, bool, error)
+	RestoreSong(id int64) (*models.Song, error)
+	PurgeSongs(before time.Time, limit int) (int64, error)
+	GetSongRevisions(id int64, page, limit int) ([]models.SongRevision, bool, error)
+	GetSongRevision(id int64, revision int) (*models.SongRevision, error)
+	RestoreSongRevision(id int64, revision int, author string) (*models.Song, error)
+}
+
+const songColumns = "id, artist_id, group_name, name, release_date, text, link, enrichment_status, enriched_at, album_id, disc_number, track_number, " + songTags + ", provenance, deleted_at"
+
+// notDeleted оставляет песни не из корзины, таблица песен должна называться songs
+const notDeleted = "songs.deleted_at IS NULL"
+
+// songTags - теги песни массивом по алфавиту, таблица песен должна называться songs
+const songTags = "ARRAY(SELECT tg.name FROM song_tags st JOIN tags tg ON tg.id = st.tag_id WHERE st.song_id = songs.id ORDER BY tg.name)"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSong читает колонки songColumns, extra - дополнительные колонки после них
+func scanSong(row rowScanner, extra ...any) (models.Song, error) {
+	var (
+		song       models.Song
+		provenance []byte
+	)
+	dest := append([]any{&song.ID, &song.ArtistID, &song.Group, &song.Name, &song.ReleaseDate, &song.Text, &song.Link, &song.EnrichmentStatus, &song.EnrichedAt, &song.AlbumID, &song.DiscNumber, &song.TrackNumber, pq.Array(&song.Tags), &provenance, &song.DeletedAt}, extra...)
+	err := row.Scan(dest...)
+	if err != nil {
+		return song, err
+	}
+
+	if provenance != nil {
+		if err := json.Unmarshal(provenance, &song.Provenance); err != nil {
+			return song, fmt.Errorf("unmarshal provenance: %w", err)
+		}
+	}
+	return song, nil
+}
+
+// marshalProvenance возвращает NULL для пустого источника, чтобы не хранить {}
+func marshalProvenance(provenance map[string]string) (any, error) {
+	if len(provenance) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(provenance)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
 type Database struct {
@@ -80,24 +212,52 @@ func New(cfg config.Database) (DBSonger, error) {
 	return &Database{Db: db}, nil
 }
 
-func (d *Database) GetSongs(group, name string, page, limit int) ([]models.Song, error) {
+func (d *Database) GetSongs(q models.SongQuery) (*models.SongList, error) {
 	const op = "internal.database.postgres.GetSongs"
-	query := "SELECT id, group_name, name, release_date, text, link FROM songs WHERE 1=1"
+
+	var args []any
+	arg := placeholders(&args)
 
 	// Filtration
-	var args []interface{}
-	if group != "" {
-		query += " AND group_name = $1"
-		args = append(args, group)
+	where := songFilters(q, arg)
+
+	var total *int64
+	if q.Count {
+		var countArgs []any
+		count, err := d.countSongs(songFilters(q, placeholders(&countArgs)), countArgs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		total = &count
+	}
+
+	// Keyset pagination: страница назад читается в обратном порядке и затем разворачивается
+	keys := sortKeys(q.Sort)
+	backward := q.Cursor != nil && q.Cursor.Backward
+	if backward {
+		for i := range keys {
+			keys[i].desc = !keys[i].desc
+		}
 	}
-	if name != "" {
-		query += " AND name = $2"
-		args = append(args, name)
+	if q.Cursor != nil {
+		condition, err := keysetCondition(keys, q.Cursor, arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		where = append(where, condition)
 	}
 
-	// Pagination
-	offset := (page - 1) * limit
-	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	query := "SELECT " + songColumns + " FROM songs"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + orderBy(keys)
+
+	// Pagination: лишняя строка показывает, есть ли следующая страница
+	query += " LIMIT " + arg(q.Limit+1)
+	if q.Cursor == nil && q.Page > 1 {
+		query += " OFFSET " + arg((q.Page-1)*q.Limit)
+	}
 
 	rows, err := d.Db.Query(query, args...)
 	if err != nil {
@@ -105,37 +265,363 @@ func (d *Database) GetSongs(group, name string, page, limit int) ([]models.Song,
 	}
 	defer rows.Close()
 
+	songs, err := scanSongs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	more := len(songs) > q.Limit
+	if more {
+		songs = songs[:q.Limit]
+	}
+	if backward {
+		slices.Reverse(songs)
+	}
+
+	list := &models.SongList{Items: songs, Total: total}
+	if len(songs) == 0 {
+		if !q.Fuzzy && q.Cursor == nil && q.Page <= 1 {
+			list.DidYouMean, err = d.didYouMean(q.Group, q.Name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		return list, nil
+	}
+
+	keys = sortKeys(q.Sort)
+	first, last := songs[0], songs[len(songs)-1]
+	if backward {
+		list.Next = cursorAt(keys, last, false)
+		if more {
+			list.Prev = cursorAt(keys, first, true)
+		}
+		return list, nil
+	}
+
+	if more {
+		list.Next = cursorAt(keys, last, false)
+	}
+	if q.Cursor != nil || q.Page > 1 {
+		list.Prev = cursorAt(keys, first, true)
+	}
+	return list, nil
+}
+
+// placeholders возвращает функцию, которая добавляет значение в args и отдаёт его плейсхолдер
+func placeholders(args *[]any) func(any) string {
+	return func(value any) string {
+		*args = append(*args, value)
+		return "$" + strconv.Itoa(len(*args))
+	}
+}
+
+// songFilters строит условия WHERE из фильтров списка. Значения передаются только плейсхолдерами
+func songFilters(q models.SongQuery, arg func(any) string) []string {
+	where := []string{notDeleted}
+	if q.ArtistID != 0 {
+		where = append(where, "artist_id = "+arg(q.ArtistID))
+	}
+	if q.Group != "" {
+		where = append(where, matchCondition("group_name", q.Group, q.Fuzzy, arg))
+	}
+	if q.Name != "" {
+		where = append(where, matchCondition("name", q.Name, q.Fuzzy, arg))
+	}
+	if q.ReleasedFrom != nil {
+		where = append(where, "song_release_date(release_date) >= "+arg(q.ReleasedFrom.Format(time.DateOnly))+"::date")
+	}
+	if q.ReleasedTo != nil {
+		where = append(where, "song_release_date(release_date) <= "+arg(q.ReleasedTo.Format(time.DateOnly))+"::date")
+	}
+	if q.HasText != nil {
+		where = append(where, presence("text", *q.HasText))
+	}
+	if q.HasLink != nil {
+		where = append(where, presence("link", *q.HasLink))
+	}
+	if len(q.Tags) > 0 {
+		where = append(where, tagCondition(q.Tags, q.TagMode, arg))
+	}
+	for _, term := range q.Search {
+		where = append(where, searchCondition(term, arg(containsPattern(term.Value))))
+	}
+
+	return where
+}
+
+// matchCondition ищет подстроку без учёта регистра, в режиме fuzzy - ещё и значения, в которых
+// есть похожий на value фрагмент (pg_trgm word_similarity, порог pg_trgm.word_similarity_threshold)
+func matchCondition(column, value string, fuzzy bool, arg func(any) string) string {
+	if !fuzzy {
+		return column + " ILIKE " + arg(containsPattern(value))
+	}
+	return "(" + column + " ILIKE " + arg(containsPattern(value)) + " OR " + arg(value) + " <% " + column + ")"
+}
+
+// didYouMean подбирает самые похожие существующие группу и название (pg_trgm similarity,
+// порог pg_trgm.similarity_threshold). nil - похожих нет
+func (d *Database) didYouMean(group, name string) (*models.SongSuggestion, error) {
+	var suggestion models.SongSuggestion
+	for _, s := range []struct {
+		column string
+		value  string
+		dest   *string
+	}{
+		{column: "group_name", value: group, dest: &suggestion.Group},
+		{column: "name", value: name, dest: &suggestion.Name},
+	} {
+		if s.value == "" {
+			continue
+		}
+
+		query := "SELECT " + s.column + " FROM songs WHERE " + notDeleted + " AND " + s.column + " % $1 ORDER BY similarity(" + s.column + ", $1) DESC, " + s.column + " LIMIT 1"
+		err := d.Db.QueryRow(query, s.value).Scan(s.dest)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("did you mean %s: %w", s.column, err)
+		}
+	}
+
+	if suggestion.Group == "" && suggestion.Name == "" {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// countSongs считает все песни, подходящие под фильтры, без учёта страницы
+func (d *Database) countSongs(where []string, args []any) (int64, error) {
+	query := "SELECT COUNT(*) FROM songs"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int64
+	if err := d.Db.QueryRow(query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return total, nil
+}
+
+// sortKey - выражение сортировки. Последний ключ всегда id, поэтому порядок однозначен
+type sortKey struct {
+	field    string
+	column   string
+	desc     bool
+	nullable bool
+}
+
+// sortColumns - выражения сортировки, для каждого есть индекс вида (выражение, id).
+// Сортировку group, name дополнительно покрывает индекс (group_name, name, id)
+var sortColumns = map[string]sortKey{
+	models.SortID:          {column: "id"},
+	models.SortGroup:       {column: "group_name"},
+	models.SortName:        {column: "name"},
+	models.SortReleaseDate: {column: "song_release_date(release_date)", nullable: true},
+}
+
+// sortKeys оставляет разрешённые поля до id включительно. Если id не указан, он добавляется
+// последним в направлении последнего поля, чтобы порядок совпадал с индексом
+func sortKeys(sort []models.SortField) []sortKey {
+	keys := make([]sortKey, 0, len(sort)+1)
+	desc := false
+	for _, field := range sort {
+		key, ok := sortColumns[field.Field]
+		if !ok {
+			continue
+		}
+		key.field, key.desc = field.Field, field.Desc
+		keys = append(keys, key)
+		if field.Field == models.SortID {
+			return keys
+		}
+		desc = field.Desc
+	}
+
+	return append(keys, sortKey{field: models.SortID, column: "id", desc: desc})
+}
+
+// orderBy строит ORDER BY. NULL идёт последним по возрастанию и первым по убыванию (как по умолчанию
+// в PostgreSQL), поэтому смена направления всех ключей даёт ровно обратный порядок
+func orderBy(keys []sortKey) string {
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.desc {
+			terms = append(terms, key.column+" DESC")
+			continue
+		}
+		terms = append(terms, key.column)
+	}
+
+	return strings.Join(terms, ", ")
+}
+
+// keysetCondition отбирает строки строго после позиции курсора в порядке keys:
+// (k1 после v1) OR (k1 = v1 AND k2 после v2) OR ... OR (k1 = v1 AND ... AND id после курсора)
+func keysetCondition(keys []sortKey, cursor *models.SongCursor, arg func(any) string) (string, error) {
+	if len(cursor.Values) != len(keys)-1 {
+		return "", errs.New(errs.ErrValidation, "cursor does not match sort order")
+	}
+
+	values := make([]any, len(keys))
+	for i, value := range cursor.Values {
+		if value == nil {
+			continue
+		}
+		if keys[i].field == models.SortReleaseDate && validation.ParseReleaseDate(*value) == nil {
+			return "", errs.New(errs.ErrValidation, "invalid cursor")
+		}
+		values[i] = *value
+	}
+	values[len(keys)-1] = cursor.ID
+
+	var (
+		branches []string
+		equal    []string
+	)
+	for i, key := range keys {
+		if after := keyAfter(key, values[i], arg); after != "" {
+			branches = append(branches, "("+strings.Join(append(equal, after), " AND ")+")")
+		}
+		equal = append(equal, keyEqual(key, values[i], arg))
+	}
+
+	if len(branches) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", nil
+}
+
+// keyAfter - значение ключа идёт после value. Пустая строка - таких значений нет
+func keyAfter(key sortKey, value any, arg func(any) string) string {
+	switch {
+	case value == nil && key.desc:
+		return key.column + " IS NOT NULL"
+	case value == nil:
+		return ""
+	case key.desc:
+		return key.column + " < " + arg(value)
+	case key.nullable:
+		return "(" + key.column + " > " + arg(value) + " OR " + key.column + " IS NULL)"
+	default:
+		return key.column + " > " + arg(value)
+	}
+}
+
+func keyEqual(key sortKey, value any, arg func(any) string) string {
+	if value == nil {
+		return key.column + " IS NULL"
+	}
+	return key.column + " = " + arg(value)
+}
+
+// cursorAt запоминает значения ключей сортировки песни
+func cursorAt(keys []sortKey, song models.Song, backward bool) *models.SongCursor {
+	cursor := &models.SongCursor{
+		Values:   make([]*string, 0, len(keys)-1),
+		ID:       song.ID,
+		Backward: backward,
+	}
+	for _, key := range keys[:len(keys)-1] {
+		var value *string
+		switch key.field {
+		case models.SortGroup:
+			value = &song.Group
+		case models.SortName:
+			value = &song.Name
+		case models.SortReleaseDate:
+			if date := validation.ParseReleaseDate(song.ReleaseDate); date != nil {
+				formatted := date.Format(time.DateOnly)
+				value = &formatted
+			}
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	return cursor
+}
+
+// searchColumns - колонки, по которым ищет условие запроса без префикса поля
+var searchColumns = map[string][]string{
+	"":                {"group_name", "name", "text"},
+	models.FieldGroup: {"group_name"},
+	models.FieldName:  {"name"},
+	models.FieldText:  {"text"},
+	models.FieldLink:  {"link"},
+}
+
+// searchCondition строит условие для одного слова запроса. Значение передаётся только
+// плейсхолдером, имена колонок берутся из searchColumns
+func searchCondition(term models.SearchTerm, placeholder string) string {
+	columns := searchColumns[term.Field]
+	if columns == nil {
+		columns = searchColumns[""]
+	}
+
+	matches := make([]string, 0, len(columns))
+	for _, column := range columns {
+		matches = append(matches, "COALESCE("+column+", '') ILIKE "+placeholder)
+	}
+
+	condition := "(" + strings.Join(matches, " OR ") + ")"
+	if term.Negate {
+		return "NOT " + condition
+	}
+	return condition
+}
+
+// presence проверяет, что колонка заполнена (has = true) или пуста
+func presence(column string, has bool) string {
+	if has {
+		return "COALESCE(" + column + ", '') <> ''"
+	}
+	return "COALESCE(" + column + ", '') = ''"
+}
+
+// containsPattern экранирует спецсимволы LIKE и ищет значение как подстроку
+func containsPattern(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func scanSongs(rows *sql.Rows) ([]models.Song, error) {
 	var songs []models.Song
 	for rows.Next() {
-		var song models.Song
-		err = rows.Scan(&song.ID, &song.Group, &song.Name, &song.ReleaseDate, &song.Text, &song.Link)
+		song, err := scanSong(rows)
 		if err != nil {
-			return nil, fmt.Errorf("%s: row scan: %w", op, err)
+			return nil, fmt.Errorf("row scan: %w", err)
 		}
 		songs = append(songs, song)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: err %w", op, err)
+		return nil, fmt.Errorf("err %w", err)
 	}
 	return songs, nil
 }
 
 func (d *Database) AddSong(song *models.Song) (int64, error) {
 	const op = "internal.database.postgres.AddSong"
-	query := "INSERT INTO songs (group_name, name, release_date, text, link) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	query := "INSERT INTO songs (group_name, name, release_date, text, link, enrichment_status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
 	var songId int64
 
-	err := d.Db.QueryRow(query, song.Group, song.Name, song.ReleaseDate, song.Text, song.Link).Scan(&songId)
+	status := song.EnrichmentStatus
+	if status == "" {
+		status = models.EnrichmentPending
+	}
+
+	err := d.Db.QueryRow(query, song.Group, song.Name, song.ReleaseDate, song.Text, song.Link, status).Scan(&songId)
 	if err != nil {
-		return 0, fmt.Errorf("%s: query row: %w", op, err)
+		return 0, fmt.Errorf("%s: query row: %w", op, classify(err))
 	}
 
 	return songId, nil
 }
 
+// DeleteSong переносит песню в корзину. Песня пропадает из списков, поиска, альбомов и плейлистов,
+// но её данные и места в альбоме и плейлистах сохраняются до восстановления или очистки корзины
 func (d *Database) DeleteSong(id int64) (int64, error) {
 	const op = "internal.database.postgres.DeleteSong"
-	query := "DELETE FROM songs WHERE id = $1"
+	query := "UPDATE songs SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
 
 	result, err := d.Db.Exec(query, id)
 	if err != nil {
@@ -150,20 +636,42 @@ func (d *Database) DeleteSong(id int64) (int64, error) {
 	return rowsAffected, nil
 }
 
-func (d *Database) UpdateSong(song *models.Song) (int64, error) {
+// UpdateSong перезаписывает песню. Изменение сохраняется ревизией с автором author
+func (d *Database) UpdateSong(song *models.Song, author string) (int64, error) {
 	const op = "internal.database.postgres.UpdateSong"
-	query := "UPDATE songs SET group_name = $1, name = $2, release_date = $3, text = $4, link = $5 WHERE id = $6"
+	query := "UPDATE songs SET group_name = $1, name = $2, release_date = $3, text = $4, link = $5, provenance = COALESCE(provenance, '{}'::jsonb) || $6::jsonb WHERE id = $7 AND deleted_at IS NULL"
+
+	// Все поля перезаписываются пользователем, источник альбома не меняется
+	provenance, err := marshalProvenance(map[string]string{
+		models.FieldReleaseDate: models.ProvenanceManual,
+		models.FieldText:        models.ProvenanceManual,
+		models.FieldLink:        models.ProvenanceManual,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: marshal provenance %w", op, err)
+	}
 
-	result, err := d.Db.Exec(query,
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := setAuthor(tx, author); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err := tx.Exec(query,
 		song.Group,
 		song.Name,
 		song.ReleaseDate,
 		song.Text,
 		song.Link,
+		provenance,
 		song.ID,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("%s: exec %w", op, err)
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -171,20 +679,453 @@ func (d *Database) UpdateSong(song *models.Song) (int64, error) {
 		return 0, fmt.Errorf("%s: rows affected %w", op, err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit %w", op, err)
+	}
 	return rowsAffected, nil
 }
 
+// patchColumns - поля, которые можно менять через PatchSong, и их колонки
+var patchColumns = map[string]string{
+	models.FieldGroup:       "group_name",
+	models.FieldName:        "name",
+	models.FieldReleaseDate: "release_date",
+	models.FieldText:        "text",
+	models.FieldLink:        "link",
+}
+
+// PatchSong обновляет только переданные поля. Очищенные поля (nil) сохраняются пустой строкой,
+// у изменённых release_date, text и link источником становится manual. Изменение сохраняется
+// ревизией с автором author
+func (d *Database) PatchSong(id int64, patch models.SongPatch, author string) (*models.Song, error) {
+	const op = "internal.database.postgres.PatchSong"
+
+	if len(patch) == 0 {
+		song, err := d.GetSong(id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return song, nil
+	}
+
+	fields := make([]string, 0, len(patch))
+	for field := range patch {
+		if _, ok := patchColumns[field]; !ok {
+			return nil, fmt.Errorf("%s: %w", op, errs.New(errs.ErrValidation, fmt.Sprintf("unknown field %q", field)))
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var (
+		sets       []string
+		args       []interface{}
+		provenance = map[string]string{}
+	)
+	for _, field := range fields {
+		value := ""
+		if patch[field] != nil {
+			value = *patch[field]
+		}
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", patchColumns[field], len(args)))
+
+		if field == models.FieldReleaseDate || field == models.FieldText || field == models.FieldLink {
+			provenance[field] = models.ProvenanceManual
+		}
+	}
+
+	if len(provenance) > 0 {
+		data, err := json.Marshal(provenance)
+		if err != nil {
+			return nil, fmt.Errorf("%s: marshal provenance %w", op, err)
+		}
+		args = append(args, data)
+		sets = append(sets, fmt.Sprintf("provenance = COALESCE(provenance, '{}'::jsonb) || $%d::jsonb", len(args)))
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE songs SET %s WHERE id = $%d AND deleted_at IS NULL RETURNING %s", strings.Join(sets, ", "), len(args), songColumns)
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := setAuthor(tx, author); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	song, err := scanSong(tx.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrSongNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, classify(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return &song, nil
+}
+
+func (d *Database) GetSong(id int64) (*models.Song, error) {
+	const op = "internal.database.postgres.GetSong"
+	query := "SELECT " + songColumns + " FROM songs WHERE id = $1 AND deleted_at IS NULL"
+
+	song, err := scanSong(d.Db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrSongNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, err)
+	}
+	return &song, nil
+}
+
 func (d *Database) GetSongText(id int64) (*models.Song, error) {
 	const op = "internal.database.postgres.GetSongText"
-	query := "SELECT id, group_name, name, release_date, text, link FROM songs WHERE id = $1"
-	var song models.Song
 
-	err := d.Db.QueryRow(query, id).Scan(&song.ID, &song.Group, &song.Name, &song.ReleaseDate, &song.Text, &song.Link)
+	song, err := d.GetSong(id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return song, nil
+}
+
+// searchSongsQuery ищет по search_vector и выделяет совпадение в самом релевантном куплете
+// (куплеты разделены пустой строкой). Если запрос не совпал ни с одним куплетом целиком,
+// например фраза на границе куплетов, фрагмент берётся из всего текста. Текст экранируется
+// (&, < и >) до выделения, поэтому фрагмент с <mark></mark> можно вставить в HTML как есть.
+// Вместо %[1]s и %[2]s подставляются условие поиска и выражение ранга
+const searchSongsQuery = `
+	SELECT ` + songColumns + `,
+		%[2]s AS rank,
+		COALESCE(verse.number, 0),
+		COALESCE(
+			ts_headline('simple', replace(replace(replace(verse.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+				query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+			ts_headline('simple', replace(replace(replace(COALESCE(songs.text, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+				query, 'MaxFragments=1, MinWords=5, MaxWords=25, StartSel=<mark>, StopSel=</mark>')
+		)
+	FROM songs
+	CROSS JOIN websearch_to_tsquery('simple', $1) AS query
+	LEFT JOIN LATERAL (
+		SELECT t.body, t.number
+		FROM regexp_split_to_table(COALESCE(songs.text, ''), '\n\n') WITH ORDINALITY AS t(body, number)
+		WHERE to_tsvector('simple', t.body) @@ query
+		ORDER BY ts_rank_cd(to_tsvector('simple', t.body), query) DESC, t.number
+		LIMIT 1
+	) AS verse ON TRUE
+	WHERE ` + notDeleted + ` AND %[1]s
+	ORDER BY rank DESC, songs.id
+	LIMIT $2 OFFSET $3`
+
+// Условие и ранг полнотекстового поиска. В режиме fuzzy находятся также группы и названия,
+// похожие на запрос (pg_trgm), и похожесть добавляется к рангу
+const (
+	ftsCondition      = "search_vector @@ query"
+	ftsRank           = "ts_rank_cd(search_vector, query)"
+	fuzzyFtsCondition = "(search_vector @@ query OR $1 <% group_name OR $1 <% name)"
+	fuzzyFtsRank      = "ts_rank_cd(search_vector, query) + word_similarity($1, group_name) + word_similarity($1, name)"
+)
+
+// SearchSongs возвращает страницу результатов и признак того, что есть следующая страница
+func (d *Database) SearchSongs(q string, fuzzy bool, page, limit int) ([]models.SongSearchResult, bool, error) {
+	const op = "internal.database.postgres.SearchSongs"
+
+	query := fmt.Sprintf(searchSongsQuery, ftsCondition, ftsRank)
+	if fuzzy {
+		query = fmt.Sprintf(searchSongsQuery, fuzzyFtsCondition, fuzzyFtsRank)
+	}
+
+	rows, err := d.Db.Query(query, q, limit+1, (page-1)*limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var results []models.SongSearchResult
+	for rows.Next() {
+		var result models.SongSearchResult
+		result.Song, err = scanSong(rows, &result.Rank, &result.Verse, &result.Snippet)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if len(results) > limit {
+		return results[:limit], true, nil
+	}
+	return results, false, nil
+}
+
+// suggestColumns - поля автодополнения, для каждого есть индекс по lower(колонка) text_pattern_ops
+var suggestColumns = map[string]string{
+	models.FieldGroup: "group_name",
+	models.FieldName:  "name",
+}
+
+// SuggestSongs возвращает самые частые значения поля, начинающиеся с prefix без учёта регистра
+func (d *Database) SuggestSongs(field, prefix string, limit int) ([]models.Suggestion, error) {
+	const op = "internal.database.postgres.SuggestSongs"
+
+	column, ok := suggestColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, errs.New(errs.ErrValidation, fmt.Sprintf("unknown field %q", field)))
+	}
+
+	query := "SELECT " + column + ", COUNT(*) FROM songs WHERE " + notDeleted + " AND lower(" + column + ") LIKE lower($1)" +
+		" GROUP BY " + column + " ORDER BY COUNT(*) DESC, " + column + " LIMIT $2"
+
+	rows, err := d.Db.Query(query, likeEscaper.Replace(prefix)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var suggestions []models.Suggestion
+	for rows.Next() {
+		var suggestion models.Suggestion
+		if err := rows.Scan(&suggestion.Value, &suggestion.Count); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return suggestions, nil
+}
+
+func (d *Database) GetSongsByStatus(status string, page, limit int) ([]models.Song, error) {
+	const op = "internal.database.postgres.GetSongsByStatus"
+	query := "SELECT " + songColumns + " FROM songs WHERE enrichment_status = $1 AND deleted_at IS NULL ORDER BY id LIMIT $2 OFFSET $3"
+
+	rows, err := d.Db.Query(query, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	songs, err := scanSongs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return songs, nil
+}
+
+// enrichedValue присваивает колонке значение из внешнего API, если поле не изменено пользователем
+// и значение не пустое
+func enrichedValue(column, field, value string) string {
+	return column + " = CASE WHEN provenance->>'" + field + "' = '" + models.ProvenanceManual + "' OR " + value + " = '' THEN " + column + " ELSE " + value + " END"
+}
+
+// manualProvenance - источники полей, изменённых пользователем
+const manualProvenance = `COALESCE((SELECT jsonb_object_agg(key, value) FROM jsonb_each(provenance) WHERE value = '"` + models.ProvenanceManual + `"'), '{}'::jsonb)`
+
+// UpdateSongDetails сохраняет детали из внешнего API. Поля, изменённые пользователем (источник manual),
+// не перезаписываются и сохраняют источник, пустые значения не затирают сохранённые. Альбом из деталей
+// создаётся и назначается песне в той же транзакции (см. attachAlbum). Источник альбома сохраняется, если альбом не менялся.
+// Автор ревизии - models.RevisionAuthorEnrichment
+func (d *Database) UpdateSongDetails(id int64, details *models.SongDetails) (int64, error) {
+	const op = "internal.database.postgres.UpdateSongDetails"
+	query := `UPDATE songs SET ` +
+		enrichedValue("release_date", models.FieldReleaseDate, "$1") + `, ` +
+		enrichedValue("text", models.FieldText, "$2") + `, ` +
+		enrichedValue("link", models.FieldLink, "$3") + `,
+		provenance = NULLIF(COALESCE(provenance, '{}'::jsonb) || COALESCE($4::jsonb, '{}'::jsonb) || ` + manualProvenance + `, '{}'::jsonb),
+		enrichment_status = $5, enriched_at = NOW() WHERE id = $6 AND deleted_at IS NULL`
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := setAuthor(tx, models.RevisionAuthorEnrichment); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	provenance := details.Provenance
+	if details.Album != nil {
+		attached, err := attachAlbum(tx, id, details.Album)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		if !attached {
+			provenance = maps.Clone(provenance)
+			delete(provenance, models.FieldAlbum)
+		}
+	}
+
+	provenanceData, err := marshalProvenance(provenance)
+	if err != nil {
+		return 0, fmt.Errorf("%s: marshal provenance %w", op, err)
+	}
+
+	result, err := tx.Exec(query, details.ReleaseDate, details.Text, details.Link, provenanceData, models.EnrichmentDone, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return rowsAffected, nil
+}
+
+func (d *Database) SetEnrichmentStatus(id int64, status string) (int64, error) {
+	const op = "internal.database.postgres.SetEnrichmentStatus"
+	query := "UPDATE songs SET enrichment_status = $1 WHERE id = $2 AND deleted_at IS NULL"
+
+	result, err := d.Db.Exec(query, status, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+
+	return rowsAffected, nil
+}
+
+// SetEnrichmentFailed помечает песню как failed. Временная ошибка (permanent = false) не меняет
+// статус песни, уже получившей детали раньше: она остаётся done со старыми данными
+func (d *Database) SetEnrichmentFailed(id int64, permanent bool) (int64, error) {
+	const op = "internal.database.postgres.SetEnrichmentFailed"
+	query := "UPDATE songs SET enrichment_status = $1 WHERE id = $2 AND deleted_at IS NULL AND ($3 OR enriched_at IS NULL)"
+
+	result, err := d.Db.Exec(query, models.EnrichmentFailed, id, permanent)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetStaleSongs возвращает обогащённые песни, данные которых получены раньше before
+func (d *Database) GetStaleSongs(before time.Time, limit int) ([]models.Song, error) {
+	const op = "internal.database.postgres.GetStaleSongs"
+	query := "SELECT " + songColumns + " FROM songs WHERE enrichment_status = $1 AND deleted_at IS NULL AND (enriched_at IS NULL OR enriched_at < $2) ORDER BY enriched_at NULLS FIRST, id LIMIT $3"
+
+	rows, err := d.Db.Query(query, models.EnrichmentDone, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	songs, err := scanSongs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return songs, nil
+}
+
+func (d *Database) GetCachedDetails(key string) (*models.CachedSongDetails, error) {
+	const op = "internal.database.postgres.GetCachedDetails"
+	query := "SELECT release_date, text, link, album, provenance, not_found, expires_at FROM song_info_cache WHERE key = $1 AND expires_at > NOW()"
+
+	var (
+		releaseDate, text, link sql.NullString
+		album, provenance       []byte
+		entry                   models.CachedSongDetails
+	)
+	err := d.Db.QueryRow(query, key).Scan(&releaseDate, &text, &link, &album, &provenance, &entry.NotFound, &entry.ExpiresAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%s: no song found", op)
+			return nil, nil
 		}
 		return nil, fmt.Errorf("%s: query row scan %w", op, err)
 	}
-	return &song, nil
+
+	if !entry.NotFound {
+		entry.Details = &models.SongDetails{
+			ReleaseDate: releaseDate.String,
+			Text:        text.String,
+			Link:        link.String,
+		}
+		if album != nil {
+			if err := json.Unmarshal(album, &entry.Details.Album); err != nil {
+				return nil, fmt.Errorf("%s: unmarshal album %w", op, err)
+			}
+		}
+		if provenance != nil {
+			if err := json.Unmarshal(provenance, &entry.Details.Provenance); err != nil {
+				return nil, fmt.Errorf("%s: unmarshal provenance %w", op, err)
+			}
+		}
+	}
+	return &entry, nil
+}
+
+func (d *Database) SetCachedDetails(key string, entry *models.CachedSongDetails) error {
+	const op = "internal.database.postgres.SetCachedDetails"
+	query := `INSERT INTO song_info_cache (key, release_date, text, link, album, provenance, not_found, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (key) DO UPDATE SET release_date = EXCLUDED.release_date, text = EXCLUDED.text,
+			link = EXCLUDED.link, album = EXCLUDED.album, provenance = EXCLUDED.provenance,
+			not_found = EXCLUDED.not_found, expires_at = EXCLUDED.expires_at`
+
+	var details models.SongDetails
+	if entry.Details != nil {
+		details = *entry.Details
+	}
+
+	provenance, err := marshalProvenance(details.Provenance)
+	if err != nil {
+		return fmt.Errorf("%s: marshal provenance %w", op, err)
+	}
+
+	var album any
+	if details.Album != nil {
+		if album, err = json.Marshal(details.Album); err != nil {
+			return fmt.Errorf("%s: marshal album %w", op, err)
+		}
+	}
+
+	_, err = d.Db.Exec(query, key, details.ReleaseDate, details.Text, details.Link, album, provenance, entry.NotFound, entry.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeCachedDetails удаляет не больше limit записей кэша, устаревших раньше before.
+// Возвращает число удалённых записей
+func (d *Database) PurgeCachedDetails(before time.Time, limit int) (int64, error) {
+	const op = "internal.database.postgres.PurgeCachedDetails"
+	query := `DELETE FROM song_info_cache WHERE key IN (
+		SELECT key FROM song_info_cache WHERE expires_at < $1 ORDER BY expires_at LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`
+
+	result, err := d.Db.Exec(query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+	return rowsAffected, nil
 }
@@ -2,13 +2,19 @@ package postgres_test
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"os"
+	"slices"
 	"song-lib/internal/database/postgres"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/models"
+	"strings"
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/pressly/goose/v3"
 )
 
 // TestMain - инициализация перед всеми тестами
@@ -41,25 +47,22 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-// createTestTables - создание тестовых таблиц
+// migrationsDir - миграции приложения: тестовая схема строится ими же, чтобы триггеры
+// и ограничения проверялись на настоящем DDL
+const migrationsDir = "../../../migrations"
+
+// createTestTables - создание тестовых таблиц миграциями
 func createTestTables(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS songs (
-			id SERIAL PRIMARY KEY,
-			group_name VARCHAR(255) NOT NULL,
-			name VARCHAR(255) NOT NULL,
-			release_date DATE,
-			text TEXT,
-			link VARCHAR(255)
-		);
-	`)
-	return err
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(db, migrationsDir)
 }
 
-// dropTestTables - удаление тестовых таблиц
+// dropTestTables - удаление тестовых таблиц откатом всех миграций
 func dropTestTables(db *sql.DB) error {
-	_, err := db.Exec("DROP TABLE IF EXISTS songs;")
-	return err
+	return goose.Reset(db, migrationsDir)
 }
 
 // TestAddSong - интеграционный тест для метода AddSong
@@ -118,8 +121,8 @@ func TestGetSongs(t *testing.T) {
 	repo := postgres.Database{Db: db}
 
 	songs := []*models.Song{
-		{Group: "Muse", Name: "Supermassive Black Hole", ReleaseDate: "2006-07-16"},
-		{Group: "Radiohead", Name: "Creep", ReleaseDate: "1993-09-21"},
+		{Group: "Muse", Name: "Supermassive Black Hole", ReleaseDate: "2006-07-16", Text: "Placeholder lyrics"},
+		{Group: "Radiohead", Name: "Creep", ReleaseDate: "21.09.1993", Link: "https://example.com/creep"},
 	}
 	for _, song := range songs {
 		_, err = repo.AddSong(song)
@@ -128,18 +131,336 @@ func TestGetSongs(t *testing.T) {
 		}
 	}
 
-	// Тестируем получение песен
-	result, err := repo.GetSongs("Muse", "", 1, 10)
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+	yes := true
+
+	tests := []struct {
+		name     string
+		query    models.SongQuery
+		expected []string
+	}{
+		{name: "by group", query: models.SongQuery{Group: "Muse"}, expected: []string{"Supermassive Black Hole"}},
+		{name: "only name, partial, any case", query: models.SongQuery{Name: "cre"}, expected: []string{"Creep"}},
+		{name: "without filters", query: models.SongQuery{}, expected: []string{"Supermassive Black Hole", "Creep"}},
+		{name: "released from", query: models.SongQuery{ReleasedFrom: &from}, expected: []string{"Supermassive Black Hole"}},
+		{name: "released to, mixed date formats", query: models.SongQuery{ReleasedTo: &to}, expected: []string{"Creep"}},
+		{name: "has link", query: models.SongQuery{HasLink: &yes}, expected: []string{"Creep"}},
+		{
+			name:     "search with phrase and negation",
+			query:    models.SongQuery{Search: []models.SearchTerm{{Value: "black hole"}, {Field: models.FieldGroup, Value: "radio", Negate: true}}},
+			expected: []string{"Supermassive Black Hole"},
+		},
+		{name: "like wildcards are literal", query: models.SongQuery{Name: "%"}, expected: nil},
+		{
+			name:     "sort by release date descending",
+			query:    models.SongQuery{Sort: []models.SortField{{Field: models.SortReleaseDate, Desc: true}}},
+			expected: []string{"Supermassive Black Hole", "Creep"},
+		},
+		{
+			name:     "sort by group descending",
+			query:    models.SongQuery{Sort: []models.SortField{{Field: models.SortGroup, Desc: true}}},
+			expected: []string{"Creep", "Supermassive Black Hole"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Page, tt.query.Limit = 1, 10
+
+			result, err := repo.GetSongs(tt.query)
+			if err != nil {
+				t.Fatalf("failed to get songs: %v", err)
+			}
+
+			if len(result.Items) != len(tt.expected) {
+				t.Fatalf("expected %d songs, got %d", len(tt.expected), len(result.Items))
+			}
+			for i, name := range tt.expected {
+				if result.Items[i].Name != name {
+					t.Errorf("expected song %s at %d, got %s", name, i, result.Items[i].Name)
+				}
+			}
+		})
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE group_name = 'Muse' OR group_name = 'Radiohead'")
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestGetSongsCursor - интеграционный тест постраничного вывода по курсору
+func TestGetSongsCursor(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	// Одинаковые и пустые даты проверяют разрешение равенства по id и NULL в сортировке
+	songs := []*models.Song{
+		{Group: "Cursor", Name: "A", ReleaseDate: "2001-01-01"},
+		{Group: "Cursor", Name: "B", ReleaseDate: "01.01.2001"},
+		{Group: "Cursor", Name: "C", ReleaseDate: ""},
+		{Group: "Cursor", Name: "D", ReleaseDate: "1999-05-05"},
+		{Group: "Cursor", Name: "E", ReleaseDate: "2010-10-10"},
+	}
+	for _, song := range songs {
+		if _, err := repo.AddSong(song); err != nil {
+			t.Fatalf("failed to add song: %v", err)
+		}
+	}
+
+	// По убыванию даты NULL идёт первым, равные даты упорядочены по убыванию id
+	expected := []string{"C", "E", "B", "A", "D"}
+	query := models.SongQuery{
+		Group: "Cursor",
+		Sort:  []models.SortField{{Field: models.SortReleaseDate, Desc: true}},
+		Page:  1,
+		Limit: 2,
+		Count: true,
+	}
+
+	// Вперёд до последней страницы
+	var (
+		names []string
+		pages []*models.SongList
+	)
+	for {
+		page, err := repo.GetSongs(query)
+		if err != nil {
+			t.Fatalf("failed to get songs: %v", err)
+		}
+		pages = append(pages, page)
+		for _, song := range page.Items {
+			names = append(names, song.Name)
+		}
+		if page.Next == nil {
+			break
+		}
+		query.Cursor = page.Next
+	}
+
+	if strings.Join(names, "") != strings.Join(expected, "") {
+		t.Fatalf("expected order %v, got %v", expected, names)
+	}
+	if len(pages) != 3 || pages[0].Prev != nil {
+		t.Fatalf("expected 3 pages without prev on the first, got %d", len(pages))
+	}
+	if pages[0].Total == nil || *pages[0].Total != int64(len(songs)) {
+		t.Errorf("expected total %d, got %v", len(songs), pages[0].Total)
+	}
+
+	// Назад с последней страницы возвращает предыдущую
+	query.Cursor = pages[2].Prev
+	page, err := repo.GetSongs(query)
+	if err != nil {
+		t.Fatalf("failed to get previous page: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Name != "B" || page.Items[1].Name != "A" || page.Prev == nil {
+		t.Errorf("unexpected previous page: %+v", page.Items)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE group_name = 'Cursor'")
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestSearchSongs - интеграционный тест полнотекстового поиска
+func TestSearchSongs(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	songs := []*models.Song{
+		{Group: "Muse", Name: "Supermassive Black Hole", Text: "First placeholder verse\nWith a second line\n\nSecond verse under a pale moon"},
+		{Group: "Hole", Name: "Celebrity Skin", Text: "Placeholder chorus"},
+	}
+	for _, song := range songs {
+		if _, err := repo.AddSong(song); err != nil {
+			t.Fatalf("failed to add song: %v", err)
+		}
+	}
+
+	// Строка из второго куплета
+	results, more, err := repo.SearchSongs(`"under a pale moon"`, false, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	if len(results) != 1 || more {
+		t.Fatalf("expected 1 result without next page, got %d", len(results))
+	}
+	if results[0].Name != "Supermassive Black Hole" || results[0].Verse != 2 {
+		t.Errorf("expected verse 2 of Supermassive Black Hole, got verse %d of %s", results[0].Verse, results[0].Name)
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>pale</mark>") {
+		t.Errorf("expected highlighted snippet, got %q", results[0].Snippet)
+	}
+
+	// Разметка в тексте экранируется, выделение остаётся
+	escaped, err := repo.AddSong(&models.Song{Group: "Escaped", Name: "Markup", Text: "<script>alert(1)</script> rock & roll"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	results, _, err = repo.SearchSongs("roll", false, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	snippet := results[0].Snippet
+	if strings.Contains(snippet, "<script>") || !strings.Contains(snippet, "&lt;script&gt;") || !strings.Contains(snippet, "&amp; <mark>roll</mark>") {
+		t.Errorf("expected escaped snippet, got %q", snippet)
+	}
+	_, _ = db.Exec("DELETE FROM songs WHERE id = $1", escaped)
+
+	// Слово находится и в группе, и в названии
+	results, _, err = repo.SearchSongs("hole", false, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	results, more, err = repo.SearchSongs("hole", false, 1, 1)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	if len(results) != 1 || !more {
+		t.Errorf("expected 1 result with next page, got %d", len(results))
+	}
+
+	// С опечаткой находится только в режиме fuzzy
+	results, _, err = repo.SearchSongs("Supermasive Black Hole", false, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no exact matches, got %d", len(results))
+	}
+
+	results, _, err = repo.SearchSongs("Supermasive Black Hole", true, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	if len(results) == 0 || results[0].Name != "Supermassive Black Hole" {
+		t.Errorf("expected fuzzy match on Supermassive Black Hole first, got %+v", results)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE group_name = 'Muse' OR group_name = 'Hole'")
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestGetSongsFuzzy - интеграционный тест поиска с опечатками и подсказки "did you mean"
+func TestGetSongsFuzzy(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	id, err := repo.AddSong(&models.Song{Group: "Metallica", Name: "Nothing Else Matters"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+
+	// Точный поиск ничего не находит, но предлагает исправление
+	list, err := repo.GetSongs(models.SongQuery{Group: "Metalica", Name: "Nothing Else Maters", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get songs: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Fatalf("expected no exact matches, got %d", len(list.Items))
+	}
+	if list.DidYouMean == nil || list.DidYouMean.Group != "Metallica" || list.DidYouMean.Name != "Nothing Else Matters" {
+		t.Errorf("unexpected suggestion: %+v", list.DidYouMean)
+	}
+
+	// В режиме fuzzy песня находится
+	list, err = repo.GetSongs(models.SongQuery{Group: "Metalica", Fuzzy: true, Page: 1, Limit: 10})
 	if err != nil {
 		t.Fatalf("failed to get songs: %v", err)
 	}
+	if len(list.Items) != 1 || list.Items[0].ID != id || list.DidYouMean != nil {
+		t.Errorf("expected fuzzy match without suggestion, got %d songs", len(list.Items))
+	}
+
+	// Удаляем данные после теста
+	_, err = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	if err != nil {
+		t.Fatalf("failed to delete added song: %v", err)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestSuggestSongs - интеграционный тест автодополнения
+func TestSuggestSongs(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	songs := []*models.Song{
+		{Group: "Muse", Name: "Uprising"},
+		{Group: "Muse", Name: "Hysteria"},
+		{Group: "Mumford & Sons", Name: "The Cave"},
+		{Group: "Radiohead", Name: "Creep"},
+	}
+	for _, song := range songs {
+		if _, err := repo.AddSong(song); err != nil {
+			t.Fatalf("failed to add song: %v", err)
+		}
+	}
+
+	suggestions, err := repo.SuggestSongs(models.FieldGroup, "mu", 10)
+	if err != nil {
+		t.Fatalf("failed to get suggestions: %v", err)
+	}
+
+	// Самое частое значение первым, без учёта регистра префикса
+	expected := []models.Suggestion{{Value: "Muse", Count: 2}, {Value: "Mumford & Sons", Count: 1}}
+	if len(suggestions) != len(expected) {
+		t.Fatalf("expected %d suggestions, got %d", len(expected), len(suggestions))
+	}
+	for i := range expected {
+		if suggestions[i] != expected[i] {
+			t.Errorf("expected %+v at %d, got %+v", expected[i], i, suggestions[i])
+		}
+	}
 
-	if len(result) != 1 {
-		t.Errorf("expected 1 song, got %d", len(result))
+	// Спецсимволы LIKE в префиксе не работают как шаблон
+	suggestions, err = repo.SuggestSongs(models.FieldName, "%", 10)
+	if err != nil {
+		t.Fatalf("failed to get suggestions: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected no suggestions, got %d", len(suggestions))
 	}
 
 	// Чистим данные после теста
-	_, _ = db.Exec("DELETE FROM songs WHERE group_name = 'Muse' OR group_name = 'Radiohead'")
+	_, _ = db.Exec("DELETE FROM songs WHERE group_name IN ('Muse', 'Mumford & Sons', 'Radiohead')")
 
 	// Закрываем базу данных
 	db.Close()
@@ -168,21 +489,37 @@ func TestDeleteSong(t *testing.T) {
 		t.Fatalf("failed to add song: %v", err)
 	}
 
-	// Тестируем удаление песни
+	// Тестируем удаление песни: песня переносится в корзину
 	_, err = repo.DeleteSong(id)
 	if err != nil {
 		t.Fatalf("failed to delete song: %v", err)
 	}
 
-	var exists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM songs WHERE id = $1)", id).Scan(&exists)
+	if _, err := repo.GetSong(id); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected deleted song to be not found, got %v", err)
+	}
+
+	var deleted bool
+	err = db.QueryRow("SELECT deleted_at IS NOT NULL FROM songs WHERE id = $1", id).Scan(&deleted)
 	if err != nil {
-		t.Fatalf("failed to check if song exists: %v", err)
+		t.Fatalf("failed to check if song is in trash: %v", err)
 	}
-	if exists {
-		t.Errorf("expected song to be deleted, but it still exists")
+	if !deleted {
+		t.Errorf("expected song to be in trash")
 	}
 
+	// Повторное удаление не находит песню
+	rowsAffected, err := repo.DeleteSong(id)
+	if err != nil {
+		t.Fatalf("failed to delete song: %v", err)
+	}
+	if rowsAffected != 0 {
+		t.Errorf("expected 0 rows affected, got %d", rowsAffected)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE id = $1", id)
+
 	// Закрываем базу данных
 	db.Close()
 }
@@ -213,7 +550,7 @@ func TestUpdateSong(t *testing.T) {
 	// Тестируем изменение песни
 	song.ID = id
 	song.Name = "Updated Song Name"
-	_, err = repo.UpdateSong(song)
+	_, err = repo.UpdateSong(song, "")
 	if err != nil {
 		t.Fatalf("failed to update song: %v", err)
 	}
@@ -281,3 +618,1126 @@ func TestGetSongText(t *testing.T) {
 	// Закрываем базу данных
 	db.Close()
 }
+
+// TestUpdateSongDetails - интеграционный тест для методов обогащения песни
+func TestUpdateSongDetails(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	id, err := repo.AddSong(&models.Song{Group: "Muse", Name: "Uprising"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+
+	// Новая песня ожидает обогащения
+	pending, err := repo.GetSongsByStatus(models.EnrichmentPending, 1, 100)
+	if err != nil {
+		t.Fatalf("failed to get pending songs: %v", err)
+	}
+	found := false
+	for _, song := range pending {
+		if song.ID == id {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected song %d to be pending", id)
+	}
+
+	// Тестируем сохранение деталей
+	_, err = repo.UpdateSongDetails(id, &models.SongDetails{ReleaseDate: "2009-09-07", Text: "Placeholder lyrics", Link: "https://example.com"})
+	if err != nil {
+		t.Fatalf("failed to update song details: %v", err)
+	}
+
+	song, err := repo.GetSongText(id)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.EnrichmentStatus != models.EnrichmentDone || song.Text != "Placeholder lyrics" {
+		t.Errorf("expected enriched song, got status: %s and text: %s", song.EnrichmentStatus, song.Text)
+	}
+
+	// Поля, изменённые пользователем, повторное обогащение не перезаписывает
+	song.Text = "Fixed lyrics"
+	if _, err := repo.UpdateSong(song, ""); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	_, err = repo.UpdateSongDetails(id, &models.SongDetails{
+		ReleaseDate: "2009-09-14",
+		Text:        "Placeholder lyrics",
+		Link:        "https://example.com/uprising",
+		Provenance: map[string]string{
+			models.FieldReleaseDate: "primary",
+			models.FieldText:        "primary",
+			models.FieldLink:        "primary",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to update song details: %v", err)
+	}
+
+	song, err = repo.GetSong(id)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.Text != "Fixed lyrics" || song.Link != "https://example.com" {
+		t.Errorf("expected manual edits to survive, got text: %s and link: %s", song.Text, song.Link)
+	}
+	if song.Provenance[models.FieldText] != models.ProvenanceManual || song.Provenance[models.FieldLink] != models.ProvenanceManual {
+		t.Errorf("expected manual provenance to be kept, got %v", song.Provenance)
+	}
+
+	// Удаляем данные после теста
+	_, err = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	if err != nil {
+		t.Fatalf("failed to delete song after test: %v", err)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestGetStaleSongs - интеграционный тест для метода GetStaleSongs
+func TestGetStaleSongs(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	id, err := repo.AddSong(&models.Song{Group: "Muse", Name: "Uprising"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	_, err = repo.UpdateSongDetails(id, &models.SongDetails{Text: "Placeholder lyrics"})
+	if err != nil {
+		t.Fatalf("failed to update song details: %v", err)
+	}
+
+	// Только что обогащённая песня не считается устаревшей
+	songs, err := repo.GetStaleSongs(time.Now().Add(-time.Hour), 100)
+	if err != nil {
+		t.Fatalf("failed to get stale songs: %v", err)
+	}
+	for _, song := range songs {
+		if song.ID == id {
+			t.Errorf("expected song %d not to be stale", id)
+		}
+	}
+
+	songs, err = repo.GetStaleSongs(time.Now().Add(time.Hour), 100)
+	if err != nil {
+		t.Fatalf("failed to get stale songs: %v", err)
+	}
+	found := false
+	for _, song := range songs {
+		if song.ID == id {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected song %d to be stale", id)
+	}
+
+	// Удаляем данные после теста
+	_, err = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	if err != nil {
+		t.Fatalf("failed to delete song after test: %v", err)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestGetSong - интеграционный тест для метода GetSong
+func TestGetSong(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	song := &models.Song{
+		Group:       "Muse",
+		Name:        "Supermassive Black Hole",
+		ReleaseDate: "2006-07-16",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+
+	id, err := repo.AddSong(song)
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+
+	// Тестируем получение песни
+	retrievedSong, err := repo.GetSong(id)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if retrievedSong.Name != song.Name || retrievedSong.Link != song.Link {
+		t.Errorf("expected song %s with link %s, got %s with link %s", song.Name, song.Link, retrievedSong.Name, retrievedSong.Link)
+	}
+
+	// Удаляем данные после теста
+	_, err = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	if err != nil {
+		t.Fatalf("failed to delete song after test: %v", err)
+	}
+
+	// Удалённая песня не найдена
+	_, err = repo.GetSong(id)
+	if !errors.Is(err, postgres.ErrSongNotFound) {
+		t.Errorf("expected song not found error, got %v", err)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestPatchSong - интеграционный тест для метода PatchSong
+func TestPatchSong(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	song := &models.Song{
+		Group:       "Muse",
+		Name:        "Supermassive Black Hole",
+		ReleaseDate: "2006-07-16",
+		Text:        "Placeholder lyrics",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+
+	id, err := repo.AddSong(song)
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+
+	// Меняем ссылку и очищаем дату, остальные поля не трогаем
+	link := "https://example.com"
+	patched, err := repo.PatchSong(id, models.SongPatch{
+		models.FieldLink:        &link,
+		models.FieldReleaseDate: nil,
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to patch song: %v", err)
+	}
+
+	if patched.Link != link || patched.ReleaseDate != "" || patched.Text != song.Text || patched.Name != song.Name {
+		t.Errorf("unexpected patched song: %+v", patched)
+	}
+	if patched.Provenance[models.FieldLink] != models.ProvenanceManual || patched.Provenance[models.FieldText] != "" {
+		t.Errorf("unexpected provenance: %v", patched.Provenance)
+	}
+
+	// Неизвестное поле - ошибка клиента
+	if _, err := repo.PatchSong(id, models.SongPatch{"album": &link}, ""); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	// Удаляем данные после теста
+	_, err = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	if err != nil {
+		t.Fatalf("failed to delete song after test: %v", err)
+	}
+
+	_, err = repo.PatchSong(id, models.SongPatch{models.FieldLink: &link}, "")
+	if !errors.Is(err, postgres.ErrSongNotFound) {
+		t.Errorf("expected song not found error, got %v", err)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestArtists - интеграционный тест исполнителей и синхронизации группы песен
+func TestArtists(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	// Новая группа песни создаёт исполнителя
+	songID, err := repo.AddSong(&models.Song{Group: "The Beatles", Name: "Yesterday"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	song, err := repo.GetSong(songID)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.ArtistID == 0 {
+		t.Fatal("expected song to be linked to an artist")
+	}
+
+	artist, err := repo.GetArtist(song.ArtistID)
+	if err != nil {
+		t.Fatalf("failed to get artist: %v", err)
+	}
+	if artist.Name != "The Beatles" || artist.SongCount != 1 {
+		t.Errorf("unexpected artist: %+v", artist)
+	}
+
+	// Переименование исполнителя меняет группу его песен
+	if _, err := repo.UpdateArtist(&models.Artist{ID: artist.ID, Name: "Beatles"}); err != nil {
+		t.Fatalf("failed to rename artist: %v", err)
+	}
+	song, err = repo.GetSong(songID)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.Group != "Beatles" || song.ArtistID != artist.ID {
+		t.Errorf("expected song of renamed artist, got group %s, artist %d", song.Group, song.ArtistID)
+	}
+
+	// Имя занято
+	otherID, err := repo.AddArtist(&models.Artist{Name: "Wings"})
+	if err != nil {
+		t.Fatalf("failed to add artist: %v", err)
+	}
+	if _, err := repo.UpdateArtist(&models.Artist{ID: otherID, Name: "Beatles"}); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+
+	// Исполнителя с песнями удалить нельзя
+	if _, err := repo.DeleteArtist(artist.ID); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if n, err := repo.DeleteArtist(otherID); err != nil || n != 1 {
+		t.Errorf("failed to delete artist without songs: %v", err)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE id = $1", songID)
+	_, _ = db.Exec("DELETE FROM artists WHERE id = $1", artist.ID)
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestAlbums - интеграционный тест альбомов: альбом из внешнего API, порядок треков и удаление альбома
+func TestAlbums(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	first, err := repo.AddSong(&models.Song{Group: "Muse", Name: "Uprising"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	second, err := repo.AddSong(&models.Song{Group: "Muse", Name: "Resistance"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+
+	// Альбом из деталей внешнего API создаётся у исполнителя песни
+	_, err = repo.UpdateSongDetails(second, &models.SongDetails{
+		Text:       "Is our secret safe tonight",
+		Album:      &models.AlbumDetails{Title: "The Resistance", TrackNumber: 2},
+		Provenance: map[string]string{models.FieldText: "default", models.FieldAlbum: "default"},
+	})
+	if err != nil {
+		t.Fatalf("failed to update song details: %v", err)
+	}
+	song, err := repo.GetSong(second)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.AlbumID == nil || song.DiscNumber == nil || *song.DiscNumber != 1 || song.TrackNumber == nil || *song.TrackNumber != 2 {
+		t.Fatalf("expected song on track 2 of disc 1, got %+v", song)
+	}
+	albumID := *song.AlbumID
+
+	album, err := repo.GetAlbum(albumID)
+	if err != nil {
+		t.Fatalf("failed to get album: %v", err)
+	}
+	if album.Title != "The Resistance" || album.Artist != "Muse" || album.TrackCount != 1 {
+		t.Errorf("unexpected album: %+v", album)
+	}
+
+	// Песня, помещённая в альбом вручную, идёт первым треком
+	trackOne := 1
+	if _, err := repo.SetAlbumTrack(albumID, first, models.Track{DiscNumber: 1, TrackNumber: &trackOne}); err != nil {
+		t.Fatalf("failed to set track: %v", err)
+	}
+	tracks, err := repo.GetAlbumTracks(albumID)
+	if err != nil {
+		t.Fatalf("failed to get tracks: %v", err)
+	}
+	if len(tracks) != 2 || tracks[0].ID != first || tracks[1].ID != second {
+		t.Errorf("unexpected track order: %+v", tracks)
+	}
+
+	// Номер трека занят
+	trackTwo := 2
+	if _, err := repo.SetAlbumTrack(albumID, first, models.Track{DiscNumber: 1, TrackNumber: &trackTwo}); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+
+	// Песню другого исполнителя нельзя поместить в альбом, а у альбома с треками нельзя сменить исполнителя
+	other, err := repo.AddSong(&models.Song{Group: "Radiohead", Name: "Airbag"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	otherSong, err := repo.GetSong(other)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if _, err := repo.SetAlbumTrack(albumID, other, models.Track{DiscNumber: 1}); !errors.Is(err, postgres.ErrTrackArtistMismatch) {
+		t.Errorf("expected artist mismatch, got %v", err)
+	}
+	album.ArtistID = otherSong.ArtistID
+	if _, err := repo.UpdateAlbum(album); !errors.Is(err, postgres.ErrAlbumHasTracks) {
+		t.Errorf("expected album with tracks conflict, got %v", err)
+	}
+
+	// Убранная вручную песня не возвращается в альбом при обогащении
+	if n, err := repo.RemoveAlbumTrack(albumID, first); err != nil || n != 1 {
+		t.Fatalf("failed to remove track: %v", err)
+	}
+	_, err = repo.UpdateSongDetails(first, &models.SongDetails{
+		Album:      &models.AlbumDetails{Title: "The Resistance", TrackNumber: 1},
+		Provenance: map[string]string{models.FieldAlbum: "default"},
+	})
+	if err != nil {
+		t.Fatalf("failed to update song details: %v", err)
+	}
+	song, err = repo.GetSong(first)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.AlbumID != nil || song.Provenance[models.FieldAlbum] != models.ProvenanceManual {
+		t.Errorf("expected song without album, got %+v", song)
+	}
+
+	// Удаление альбома оставляет песни без альбома
+	if n, err := repo.DeleteAlbum(albumID); err != nil || n != 1 {
+		t.Fatalf("failed to delete album: %v", err)
+	}
+	song, err = repo.GetSong(second)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if song.AlbumID != nil || song.DiscNumber != nil || song.TrackNumber != nil {
+		t.Errorf("expected song without album, got %+v", song)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE id IN ($1, $2, $3)", first, second, other)
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestSongGroupChangeLeavesAlbum - интеграционный тест смены группы у песни в альбоме: PUT, PATCH
+// и откат к ревизии с другой группой убирают песню из альбома прежнего исполнителя
+func TestSongGroupChangeLeavesAlbum(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	song := &models.Song{Group: "Blur", Name: "Song 2"}
+	id, err := repo.AddSong(song)
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	song.ID = id
+
+	// setTrack помещает песню вторым треком альбома Blur
+	setTrack := func() int64 {
+		t.Helper()
+		_, err := repo.UpdateSongDetails(id, &models.SongDetails{
+			Album:      &models.AlbumDetails{Title: "Blur", TrackNumber: 2},
+			Provenance: map[string]string{models.FieldAlbum: "default"},
+		})
+		if err != nil {
+			t.Fatalf("failed to update song details: %v", err)
+		}
+		got, err := repo.GetSong(id)
+		if err != nil {
+			t.Fatalf("failed to get song: %v", err)
+		}
+		if got.AlbumID == nil || got.TrackNumber == nil || *got.TrackNumber != 2 {
+			t.Fatalf("expected song on track 2, got %+v", got)
+		}
+		return *got.AlbumID
+	}
+	// expectNoAlbum проверяет, что песня с новой группой не осталась в альбоме
+	expectNoAlbum := func(step, group string) {
+		t.Helper()
+		got, err := repo.GetSong(id)
+		if err != nil {
+			t.Fatalf("failed to get song: %v", err)
+		}
+		if got.Group != group || got.AlbumID != nil || got.DiscNumber != nil || got.TrackNumber != nil {
+			t.Errorf("%s: expected song of %s without album, got %+v", step, group, got)
+		}
+	}
+
+	albumID := setTrack()
+
+	// Смена группы через PUT
+	song.Group = "Gorillaz"
+	if _, err := repo.UpdateSong(song, "alice"); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	expectNoAlbum("PUT", "Gorillaz")
+
+	// Смена группы через PATCH
+	group := "Blur"
+	if _, err := repo.PatchSong(id, models.SongPatch{models.FieldGroup: &group}, "bob"); err != nil {
+		t.Fatalf("failed to patch song: %v", err)
+	}
+	if _, err := repo.SetAlbumTrack(albumID, id, models.Track{DiscNumber: 1}); err != nil {
+		t.Fatalf("failed to set track: %v", err)
+	}
+	group = "The Good, the Bad & the Queen"
+	if _, err := repo.PatchSong(id, models.SongPatch{models.FieldGroup: &group}, "bob"); err != nil {
+		t.Fatalf("failed to patch song: %v", err)
+	}
+	expectNoAlbum("PATCH", group)
+
+	// Откат к ревизии с группой Gorillaz
+	revisions, _, err := repo.GetSongRevisions(id, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get revisions: %v", err)
+	}
+	var gorillaz int
+	for _, revision := range revisions {
+		if revision.Group == "Gorillaz" {
+			gorillaz = revision.Revision
+		}
+	}
+	if gorillaz == 0 {
+		t.Fatalf("expected revision with group Gorillaz, got %+v", revisions)
+	}
+	song.Group = "Blur"
+	if _, err := repo.UpdateSong(song, "alice"); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	if _, err := repo.SetAlbumTrack(albumID, id, models.Track{DiscNumber: 1}); err != nil {
+		t.Fatalf("failed to set track: %v", err)
+	}
+	if _, err := repo.RestoreSongRevision(id, gorillaz, "carol"); err != nil {
+		t.Fatalf("failed to restore revision: %v", err)
+	}
+	expectNoAlbum("restore", "Gorillaz")
+
+	// Изменение без смены группы оставляет песню в альбоме
+	song.Group = "Blur"
+	if _, err := repo.UpdateSong(song, "alice"); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	if _, err := repo.SetAlbumTrack(albumID, id, models.Track{DiscNumber: 1}); err != nil {
+		t.Fatalf("failed to set track: %v", err)
+	}
+	if _, err := repo.UpdateSong(song, "alice"); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	got, err := repo.GetSong(id)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	if got.AlbumID == nil || *got.AlbumID != albumID {
+		t.Errorf("expected song to stay in album %d, got %+v", albumID, got)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	_, _ = db.Exec("DELETE FROM albums WHERE id = $1", albumID)
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestTags - интеграционный тест тегов: замена набора, фильтр с режимами all и any, облако тегов
+func TestTags(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	songs := map[string][]string{
+		"Creep":                   {"live", "rock"},
+		"Supermassive Black Hole": {"rock"},
+		"Nothing Else Matters":    {"ballad", "live"},
+	}
+	ids := make(map[string]int64, len(songs))
+	for name, tags := range songs {
+		id, err := repo.AddSong(&models.Song{Group: "Tagged", Name: name})
+		if err != nil {
+			t.Fatalf("failed to add song: %v", err)
+		}
+		ids[name] = id
+
+		song, err := repo.SetSongTags(id, tags)
+		if err != nil {
+			t.Fatalf("failed to set tags: %v", err)
+		}
+		if !slices.Equal(song.Tags, tags) {
+			t.Errorf("expected tags %v, got %v", tags, song.Tags)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		mode     string
+		expected []string
+	}{
+		{name: "all of one", tags: []string{"rock"}, mode: models.TagModeAll, expected: []string{"Creep", "Supermassive Black Hole"}},
+		{name: "all of two", tags: []string{"live", "rock"}, mode: models.TagModeAll, expected: []string{"Creep"}},
+		{name: "any of two", tags: []string{"ballad", "rock"}, mode: models.TagModeAny, expected: []string{"Creep", "Supermassive Black Hole", "Nothing Else Matters"}},
+		{name: "unknown tag", tags: []string{"jazz"}, mode: models.TagModeAny, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := repo.GetSongs(models.SongQuery{Group: "Tagged", Tags: tt.tags, TagMode: tt.mode, Page: 1, Limit: 10})
+			if err != nil {
+				t.Fatalf("failed to get songs: %v", err)
+			}
+
+			var names []string
+			for _, song := range list.Items {
+				names = append(names, song.Name)
+			}
+			slices.Sort(names)
+			expected := slices.Clone(tt.expected)
+			slices.Sort(expected)
+			if !slices.Equal(names, expected) {
+				t.Errorf("expected %v, got %v", expected, names)
+			}
+		})
+	}
+
+	// Облако тегов: самые частые первыми
+	cloud, err := repo.GetTagCloud(2)
+	if err != nil {
+		t.Fatalf("failed to get tag cloud: %v", err)
+	}
+	expected := []models.TagCount{{Name: "live", Count: 2}, {Name: "rock", Count: 2}}
+	if !slices.Equal(cloud, expected) {
+		t.Errorf("expected tag cloud %v, got %v", expected, cloud)
+	}
+
+	// Пустой набор удаляет все теги песни
+	song, err := repo.SetSongTags(ids["Creep"], []string{})
+	if err != nil {
+		t.Fatalf("failed to clear tags: %v", err)
+	}
+	if len(song.Tags) != 0 {
+		t.Errorf("expected no tags, got %v", song.Tags)
+	}
+
+	if _, err := repo.SetSongTags(-1, []string{"rock"}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// Чистим данные после теста
+	for _, id := range ids {
+		_, _ = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestPlaylists - интеграционный тест плейлистов: порядок записей, перестановка и перенос
+func TestPlaylists(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	ids := make(map[string]int64)
+	for _, name := range []string{"Creep", "Karma Police", "No Surprises", "Lucky"} {
+		id, err := repo.AddSong(&models.Song{Group: "Radiohead", Name: name})
+		if err != nil {
+			t.Fatalf("failed to add song: %v", err)
+		}
+		ids[name] = id
+	}
+
+	playlistID, err := repo.AddPlaylist(&models.Playlist{Name: "OK Computer", AllowDuplicates: false})
+	if err != nil {
+		t.Fatalf("failed to add playlist: %v", err)
+	}
+
+	// order возвращает названия песен плейлиста по порядку и проверяет позиции 1..n
+	order := func() ([]string, []int64) {
+		t.Helper()
+		entries, _, err := repo.GetPlaylistEntries(playlistID, 1, 10)
+		if err != nil {
+			t.Fatalf("failed to get entries: %v", err)
+		}
+		var names []string
+		var entryIDs []int64
+		for i, entry := range entries {
+			if entry.Position != i+1 {
+				t.Errorf("expected position %d, got %d", i+1, entry.Position)
+			}
+			names = append(names, entry.Song.Name)
+			entryIDs = append(entryIDs, entry.ID)
+		}
+		return names, entryIDs
+	}
+
+	// Без позиции песня добавляется в конец, с позицией - вставляется со сдвигом
+	for _, name := range []string{"Creep", "Karma Police", "Lucky"} {
+		if _, err := repo.AddPlaylistEntry(playlistID, ids[name], 0); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+	entry, err := repo.AddPlaylistEntry(playlistID, ids["No Surprises"], 2)
+	if err != nil {
+		t.Fatalf("failed to insert entry: %v", err)
+	}
+	if entry.Position != 2 {
+		t.Errorf("expected position 2, got %d", entry.Position)
+	}
+	names, entryIDs := order()
+	if expected := []string{"Creep", "No Surprises", "Karma Police", "Lucky"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	// Повтор запрещён, пока плейлист не разрешает повторы
+	if _, err := repo.AddPlaylistEntry(playlistID, ids["Creep"], 0); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if _, err := repo.AddPlaylistEntry(playlistID, -1, 0); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// Перемещение первой записи за конец ставит её последней
+	if _, err := repo.MovePlaylistEntry(playlistID, entryIDs[0], 10); err != nil {
+		t.Fatalf("failed to move entry: %v", err)
+	}
+	names, entryIDs = order()
+	if expected := []string{"No Surprises", "Karma Police", "Lucky", "Creep"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	// Новый порядок должен перечислять все записи ровно по одному разу
+	if err := repo.ReorderPlaylist(playlistID, entryIDs[:3]); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if err := repo.ReorderPlaylist(playlistID, []int64{entryIDs[0], entryIDs[0], entryIDs[1], entryIDs[2]}); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	slices.Reverse(entryIDs)
+	if err := repo.ReorderPlaylist(playlistID, entryIDs); err != nil {
+		t.Fatalf("failed to reorder playlist: %v", err)
+	}
+	names, _ = order()
+	if expected := []string{"Creep", "Lucky", "Karma Police", "No Surprises"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	// Песня в корзине пропадает из плейлиста без пропусков в позициях
+	if _, err := repo.DeleteSong(ids["Lucky"]); err != nil {
+		t.Fatalf("failed to delete song: %v", err)
+	}
+	names, _ = order()
+	if expected := []string{"Creep", "Karma Police", "No Surprises"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+	playlist, err := repo.GetPlaylist(playlistID)
+	if err != nil {
+		t.Fatalf("failed to get playlist: %v", err)
+	}
+	if playlist.EntryCount != 3 {
+		t.Errorf("expected 3 entries, got %d", playlist.EntryCount)
+	}
+
+	// Повторы нельзя разрешить и снова запретить, если они уже есть
+	playlist.AllowDuplicates = true
+	if err := repo.UpdatePlaylist(playlist); err != nil {
+		t.Fatalf("failed to update playlist: %v", err)
+	}
+	if _, err := repo.AddPlaylistEntry(playlistID, ids["Creep"], 1); err != nil {
+		t.Fatalf("failed to add duplicate entry: %v", err)
+	}
+	playlist.AllowDuplicates = false
+	if err := repo.UpdatePlaylist(playlist); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+
+	// Восстановленная песня возвращается на своё место
+	if _, err := repo.RestoreSong(ids["Lucky"]); err != nil {
+		t.Fatalf("failed to restore song: %v", err)
+	}
+	names, _ = order()
+	if expected := []string{"Creep", "Creep", "Lucky", "Karma Police", "No Surprises"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	// Чистим данные после теста
+	if _, err := repo.DeletePlaylist(playlistID); err != nil {
+		t.Errorf("failed to delete playlist: %v", err)
+	}
+	for _, id := range ids {
+		_, _ = db.Exec("DELETE FROM songs WHERE id = $1", id)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestTrash - интеграционный тест для корзины: скрытие, восстановление и очистка
+func TestTrash(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	id, err := repo.AddSong(&models.Song{Group: "Trashed", Name: "Paranoid Android", Text: "Placeholder verse about the noise"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	if _, err := repo.SetSongTags(id, []string{"trashed"}); err != nil {
+		t.Fatalf("failed to set tags: %v", err)
+	}
+
+	if _, err := repo.DeleteSong(id); err != nil {
+		t.Fatalf("failed to delete song: %v", err)
+	}
+
+	// Песня в корзине не видна в списке, поиске, подсказках и облаке тегов
+	list, err := repo.GetSongs(models.SongQuery{Group: "Trashed", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get songs: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("expected no songs, got %d", len(list.Items))
+	}
+	results, _, err := repo.SearchSongs("noise", false, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to search songs: %v", err)
+	}
+	for _, result := range results {
+		if result.ID == id {
+			t.Errorf("expected deleted song not to be found")
+		}
+	}
+	suggestions, err := repo.SuggestSongs(models.FieldGroup, "Trash", 10)
+	if err != nil {
+		t.Fatalf("failed to suggest songs: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected no suggestions, got %v", suggestions)
+	}
+	cloud, err := repo.GetTagCloud(200)
+	if err != nil {
+		t.Fatalf("failed to get tag cloud: %v", err)
+	}
+	for _, tag := range cloud {
+		if tag.Name == "trashed" {
+			t.Errorf("expected tag of deleted song not to be in cloud")
+		}
+	}
+	if _, err := repo.PatchSong(id, models.SongPatch{models.FieldName: nil}, ""); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	trash, _, err := repo.GetTrash(1, 100)
+	if err != nil {
+		t.Fatalf("failed to get trash: %v", err)
+	}
+	if !slices.ContainsFunc(trash, func(song models.Song) bool { return song.ID == id && song.DeletedAt != nil }) {
+		t.Errorf("expected song in trash")
+	}
+
+	// Восстановление возвращает песню с тегами
+	song, err := repo.RestoreSong(id)
+	if err != nil {
+		t.Fatalf("failed to restore song: %v", err)
+	}
+	if song.DeletedAt != nil || !slices.Equal(song.Tags, []string{"trashed"}) {
+		t.Errorf("expected restored song with tags, got %+v", song)
+	}
+	if _, err := repo.RestoreSong(id); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// Очистка удаляет только песни, попавшие в корзину раньше срока
+	if _, err := repo.DeleteSong(id); err != nil {
+		t.Fatalf("failed to delete song: %v", err)
+	}
+	purged, err := repo.PurgeSongs(time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("failed to purge songs: %v", err)
+	}
+	if purged != 0 {
+		t.Errorf("expected no songs purged, got %d", purged)
+	}
+	if _, err := repo.PurgeSongs(time.Now().Add(time.Hour), 10); err != nil {
+		t.Fatalf("failed to purge songs: %v", err)
+	}
+	if _, err := repo.RestoreSong(id); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected purged song to be gone, got %v", err)
+	}
+
+	// Песня в корзине не занимает номер трека, а восстановленная на занятое место остаётся без номера
+	old, err := repo.AddSong(&models.Song{Group: "Trashed", Name: "Airbag"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	replacement, err := repo.AddSong(&models.Song{Group: "Trashed", Name: "Lucky"})
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	song, err = repo.GetSong(old)
+	if err != nil {
+		t.Fatalf("failed to get song: %v", err)
+	}
+	artistID := song.ArtistID
+	albumID, err := repo.AddAlbum(&models.Album{ArtistID: artistID, Title: "OK Computer"})
+	if err != nil {
+		t.Fatalf("failed to add album: %v", err)
+	}
+	trackOne := 1
+	if _, err := repo.SetAlbumTrack(albumID, old, models.Track{DiscNumber: 1, TrackNumber: &trackOne}); err != nil {
+		t.Fatalf("failed to set track: %v", err)
+	}
+	if _, err := repo.DeleteSong(old); err != nil {
+		t.Fatalf("failed to delete song: %v", err)
+	}
+	if _, err := repo.SetAlbumTrack(albumID, replacement, models.Track{DiscNumber: 1, TrackNumber: &trackOne}); err != nil {
+		t.Fatalf("expected track of deleted song to be free, got %v", err)
+	}
+	song, err = repo.RestoreSong(old)
+	if err != nil {
+		t.Fatalf("failed to restore song: %v", err)
+	}
+	if song.AlbumID == nil || *song.AlbumID != albumID || song.TrackNumber != nil {
+		t.Errorf("expected restored song in album without track number, got %+v", song)
+	}
+
+	// Исполнителя, у которого остались только песни в корзине, удалить нельзя
+	if _, err := repo.DeleteAlbum(albumID); err != nil {
+		t.Fatalf("failed to delete album: %v", err)
+	}
+	for _, songID := range []int64{old, replacement} {
+		if _, err := repo.DeleteSong(songID); err != nil {
+			t.Fatalf("failed to delete song: %v", err)
+		}
+	}
+	if _, err := repo.DeleteArtist(artistID); !errors.Is(err, postgres.ErrArtistHasTrashedSongs) {
+		t.Errorf("expected trashed songs conflict, got %v", err)
+	}
+	if _, err := repo.PurgeSongs(time.Now().Add(time.Hour), 100); err != nil {
+		t.Fatalf("failed to purge songs: %v", err)
+	}
+	if _, err := repo.DeleteArtist(artistID); err != nil {
+		t.Errorf("failed to delete artist: %v", err)
+	}
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestSongRevisions - интеграционный тест истории песни: авторы ревизий и откат
+func TestSongRevisions(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	song := &models.Song{Group: "Revised", Name: "Let Down", Text: "First line\nSecond line"}
+	id, err := repo.AddSong(song)
+	if err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	song.ID = id
+
+	// Изменение с автором создаёт вторую ревизию
+	song.Text = "First line\nSecond line\nThird line"
+	if _, err := repo.UpdateSong(song, "alice"); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	// Изменение без новых значений ревизию не создаёт
+	if _, err := repo.UpdateSong(song, "alice"); err != nil {
+		t.Fatalf("failed to update song: %v", err)
+	}
+	link := "https://example.com/let-down"
+	if _, err := repo.PatchSong(id, models.SongPatch{models.FieldLink: &link}, "bob"); err != nil {
+		t.Fatalf("failed to patch song: %v", err)
+	}
+
+	revisions, more, err := repo.GetSongRevisions(id, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get revisions: %v", err)
+	}
+	if more || len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revisions))
+	}
+	var authors []string
+	for _, revision := range revisions {
+		authors = append(authors, revision.Author)
+	}
+	if expected := []string{"bob", "alice", ""}; !slices.Equal(authors, expected) {
+		t.Errorf("expected authors %v, got %v", expected, authors)
+	}
+	if revisions[0].Revision != 3 || revisions[0].Link != link {
+		t.Errorf("expected latest revision 3 with link, got %+v", revisions[0])
+	}
+
+	first, err := repo.GetSongRevision(id, 1)
+	if err != nil {
+		t.Fatalf("failed to get revision: %v", err)
+	}
+	if first.Text != "First line\nSecond line" {
+		t.Errorf("expected original text, got %q", first.Text)
+	}
+	if _, err := repo.GetSongRevision(id, 10); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// Откат к первой ревизии создаёт новую ревизию
+	restored, err := repo.RestoreSongRevision(id, 1, "carol")
+	if err != nil {
+		t.Fatalf("failed to restore revision: %v", err)
+	}
+	if restored.Text != first.Text || restored.Link != "" || restored.Provenance[models.FieldText] != models.ProvenanceManual {
+		t.Errorf("expected song from revision 1, got %+v", restored)
+	}
+	latest, err := repo.GetSongRevision(id, 4)
+	if err != nil {
+		t.Fatalf("failed to get revision: %v", err)
+	}
+	if latest.Author != "carol" || latest.Text != first.Text {
+		t.Errorf("expected rollback revision by carol, got %+v", latest)
+	}
+
+	if _, err := repo.RestoreSongRevision(id, 10, "carol"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM songs WHERE id = $1", id)
+
+	// Закрываем базу данных
+	db.Close()
+}
+
+// TestCachedDetails - интеграционный тест кэша ответов внешнего API в postgres: промах, попадание,
+// устаревшая запись, перезапись и удаление устаревших записей порциями
+func TestCachedDetails(t *testing.T) {
+	dsn := "host=localhost user=postgres password=postgres dbname=testdb sslmode=disable"
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	repo := postgres.Database{Db: db}
+
+	// Промах
+	entry, err := repo.GetCachedDetails("cache:missing")
+	if err != nil || entry != nil {
+		t.Fatalf("expected cache miss, got %+v, %v", entry, err)
+	}
+
+	// Попадание возвращает детали с альбомом и источниками
+	details := &models.SongDetails{
+		ReleaseDate: "16.07.2006",
+		Text:        "Placeholder verse one of Supermassive Black Hole",
+		Link:        "https://example.com/supermassive",
+		Album:       &models.AlbumDetails{Title: "Black Holes and Revelations", TrackNumber: 3},
+		Provenance:  map[string]string{models.FieldText: "default", models.FieldAlbum: "default"},
+	}
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	if err := repo.SetCachedDetails("cache:hit", &models.CachedSongDetails{Details: details, ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("failed to set cache entry: %v", err)
+	}
+	entry, err = repo.GetCachedDetails("cache:hit")
+	if err != nil || entry == nil {
+		t.Fatalf("expected cache hit, got %+v, %v", entry, err)
+	}
+	if entry.NotFound || !entry.ExpiresAt.Equal(expiresAt) || entry.Details == nil ||
+		entry.Details.Text != details.Text || entry.Details.Link != details.Link ||
+		entry.Details.Album == nil || *entry.Details.Album != *details.Album ||
+		entry.Details.Provenance[models.FieldAlbum] != "default" {
+		t.Errorf("unexpected cache entry: %+v, details %+v", entry, entry.Details)
+	}
+
+	// Перезапись заменяет детали отрицательным ответом
+	if err := repo.SetCachedDetails("cache:hit", &models.CachedSongDetails{NotFound: true, ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("failed to overwrite cache entry: %v", err)
+	}
+	entry, err = repo.GetCachedDetails("cache:hit")
+	if err != nil || entry == nil || !entry.NotFound || entry.Details != nil {
+		t.Errorf("expected negative cache entry, got %+v, %v", entry, err)
+	}
+
+	// Устаревшая запись не возвращается
+	past := time.Now().Add(-time.Hour)
+	for _, key := range []string{"cache:expired-1", "cache:expired-2", "cache:expired-3"} {
+		if err := repo.SetCachedDetails(key, &models.CachedSongDetails{Details: details, ExpiresAt: past}); err != nil {
+			t.Fatalf("failed to set cache entry: %v", err)
+		}
+	}
+	entry, err = repo.GetCachedDetails("cache:expired-1")
+	if err != nil || entry != nil {
+		t.Errorf("expected expired entry to miss, got %+v, %v", entry, err)
+	}
+
+	// Устаревшие записи удаляются порциями, действующие остаются
+	if n, err := repo.PurgeCachedDetails(time.Now(), 2); err != nil || n != 2 {
+		t.Errorf("expected 2 purged entries, got %d, %v", n, err)
+	}
+	if n, err := repo.PurgeCachedDetails(time.Now(), 2); err != nil || n != 1 {
+		t.Errorf("expected 1 purged entry, got %d, %v", n, err)
+	}
+	var left int
+	if err := db.QueryRow("SELECT COUNT(*) FROM song_info_cache WHERE key LIKE 'cache:%'").Scan(&left); err != nil {
+		t.Fatalf("failed to count cache entries: %v", err)
+	}
+	if left != 1 {
+		t.Errorf("expected only the fresh entry to stay, got %d entries", left)
+	}
+
+	// Чистим данные после теста
+	_, _ = db.Exec("DELETE FROM song_info_cache WHERE key LIKE 'cache:%'")
+
+	// Закрываем базу данных
+	db.Close()
+}
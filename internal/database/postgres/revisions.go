@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+)
+
+// ErrRevisionNotFound - у песни нет ревизии с таким номером
+var ErrRevisionNotFound error = errs.New(errs.ErrNotFound, "revision not found")
+
+const revisionColumns = "song_id, revision, author, created_at, group_name, name, COALESCE(release_date, ''), COALESCE(text, ''), COALESCE(link, '')"
+
+func scanRevision(row rowScanner) (models.SongRevision, error) {
+	var revision models.SongRevision
+	err := row.Scan(&revision.SongID, &revision.Revision, &revision.Author, &revision.CreatedAt,
+		&revision.Group, &revision.Name, &revision.ReleaseDate, &revision.Text, &revision.Link)
+	return revision, err
+}
+
+// setAuthor передаёт автора изменения триггеру ревизий до конца транзакции
+func setAuthor(tx *sql.Tx, author string) error {
+	if _, err := tx.Exec("SELECT set_config('songlib.author', $1, true)", author); err != nil {
+		return fmt.Errorf("set author: %w", err)
+	}
+	return nil
+}
+
+// GetSongRevisions возвращает страницу ревизий песни, новые первыми, и признак того, что есть следующая страница
+func (d *Database) GetSongRevisions(id int64, page, limit int) ([]models.SongRevision, bool, error) {
+	const op = "internal.database.postgres.GetSongRevisions"
+	query := "SELECT " + revisionColumns + " FROM song_revisions WHERE song_id = $1 ORDER BY revision DESC LIMIT $2 OFFSET $3"
+
+	rows, err := d.Db.Query(query, id, limit+1, (page-1)*limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var revisions []models.SongRevision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if len(revisions) > limit {
+		return revisions[:limit], true, nil
+	}
+	return revisions, false, nil
+}
+
+func (d *Database) GetSongRevision(id int64, revision int) (*models.SongRevision, error) {
+	const op = "internal.database.postgres.GetSongRevision"
+	query := "SELECT " + revisionColumns + " FROM song_revisions WHERE song_id = $1 AND revision = $2"
+
+	rev, err := scanRevision(d.Db.QueryRow(query, id, revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrRevisionNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, err)
+	}
+	return &rev, nil
+}
+
+// RestoreSongRevision возвращает песне группу, название, дату выхода, текст и ссылку из ревизии.
+// Откат - изменение пользователя: он сохраняется новой ревизией с автором author, а у даты выхода,
+// текста и ссылки источником становится manual
+func (d *Database) RestoreSongRevision(id int64, revision int, author string) (*models.Song, error) {
+	const op = "internal.database.postgres.RestoreSongRevision"
+	query := `UPDATE songs SET (group_name, name, release_date, text, link) = (
+			SELECT group_name, name, release_date, text, link FROM song_revisions WHERE song_id = $1 AND revision = $2
+		), provenance = COALESCE(provenance, '{}'::jsonb) || $3::jsonb
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING ` + songColumns
+
+	provenance, err := marshalProvenance(map[string]string{
+		models.FieldReleaseDate: models.ProvenanceManual,
+		models.FieldText:        models.ProvenanceManual,
+		models.FieldLink:        models.ProvenanceManual,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal provenance %w", op, err)
+	}
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM song_revisions WHERE song_id = $1 AND revision = $2)", id, revision).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: check revision %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, ErrRevisionNotFound)
+	}
+
+	if err := setAuthor(tx, author); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	song, err := scanSong(tx.QueryRow(query, id, revision, provenance))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrSongNotFound)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, classify(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return &song, nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"song-lib/internal/models"
+)
+
+// tagCondition оставляет песни со всеми тегами (TagModeAll) или хотя бы с одним из них (TagModeAny).
+// Теги должны быть нормализованы и без повторов, таблица песен должна называться songs
+func tagCondition(tags []string, mode string, arg func(any) string) string {
+	matched := "SELECT 1 FROM song_tags st JOIN tags tg ON tg.id = st.tag_id WHERE st.song_id = songs.id AND tg.name = ANY(" + arg(pq.Array(tags)) + ")"
+	if mode == models.TagModeAny {
+		return "EXISTS (" + matched + ")"
+	}
+	return "(SELECT COUNT(*) FROM (" + matched + ") AS matched) = " + arg(len(tags))
+}
+
+// SetSongTags заменяет теги песни и возвращает песню с новыми тегами. Новые теги создаются,
+// теги без песен остаются в таблице tags, но не попадают в облако тегов
+func (d *Database) SetSongTags(id int64, tags []string) (*models.Song, error) {
+	const op = "internal.database.postgres.SetSongTags"
+
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// Блокировка песни не даёт параллельным запросам смешать два набора тегов
+	if err := tx.QueryRow("SELECT id FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrSongNotFound)
+		}
+		return nil, fmt.Errorf("%s: lock song %w", op, err)
+	}
+
+	names := pq.Array(tags)
+	if _, err := tx.Exec("INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING", names); err != nil {
+		return nil, fmt.Errorf("%s: insert tags %w", op, classify(err))
+	}
+	if _, err := tx.Exec("DELETE FROM song_tags WHERE song_id = $1 AND tag_id NOT IN (SELECT id FROM tags WHERE name = ANY($2))", id, names); err != nil {
+		return nil, fmt.Errorf("%s: detach tags %w", op, err)
+	}
+	if _, err := tx.Exec("INSERT INTO song_tags (song_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2) ON CONFLICT DO NOTHING", id, names); err != nil {
+		return nil, fmt.Errorf("%s: attach tags %w", op, err)
+	}
+
+	song, err := scanSong(tx.QueryRow("SELECT "+songColumns+" FROM songs WHERE id = $1", id))
+	if err != nil {
+		return nil, fmt.Errorf("%s: query row scan %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit %w", op, err)
+	}
+	return &song, nil
+}
+
+// GetTagCloud возвращает самые частые теги и число песен с каждым из них
+func (d *Database) GetTagCloud(limit int) ([]models.TagCount, error) {
+	const op = "internal.database.postgres.GetTagCloud"
+	query := "SELECT tg.name, COUNT(*) FROM tags tg JOIN song_tags st ON st.tag_id = tg.id JOIN songs s ON s.id = st.song_id WHERE s.deleted_at IS NULL GROUP BY tg.name ORDER BY COUNT(*) DESC, tg.name LIMIT $1"
+
+	rows, err := d.Db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	var tags []models.TagCount
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"song-lib/internal/models"
+	"time"
+)
+
+// GetTrash возвращает страницу песен из корзины, недавно удалённые первыми,
+// и признак того, что есть следующая страница
+func (d *Database) GetTrash(page, limit int) ([]models.Song, bool, error) {
+	const op = "internal.database.postgres.GetTrash"
+	query := "SELECT " + songColumns + " FROM songs WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT $1 OFFSET $2"
+
+	rows, err := d.Db.Query(query, limit+1, (page-1)*limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: query %w", op, err)
+	}
+	defer rows.Close()
+
+	songs, err := scanSongs(rows)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(songs) > limit {
+		return songs[:limit], true, nil
+	}
+	return songs, false, nil
+}
+
+// RestoreSong возвращает песню из корзины вместе с её тегами и местами в альбоме и плейлистах.
+// Если номер трека в альбоме за это время занят другой песней, песня остаётся в альбоме без номера
+func (d *Database) RestoreSong(id int64) (*models.Song, error) {
+	const op = "internal.database.postgres.RestoreSong"
+	query := `UPDATE songs SET deleted_at = NULL,
+		track_number = CASE WHEN EXISTS (
+			SELECT 1 FROM songs t
+			WHERE t.album_id = songs.album_id AND t.disc_number = songs.disc_number AND t.track_number = songs.track_number AND t.deleted_at IS NULL
+		) THEN NULL ELSE track_number END
+		WHERE id = $1 AND deleted_at IS NOT NULL RETURNING ` + songColumns
+
+	song, err := scanSong(d.Db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrSongNotInTrash)
+		}
+		return nil, fmt.Errorf("%s: query row scan %w", op, classify(err))
+	}
+	return &song, nil
+}
+
+// PurgeSongs удаляет навсегда не больше limit песен, попавших в корзину раньше before.
+// Теги и записи в плейлистах удаляются каскадом. Возвращает число удалённых песен
+func (d *Database) PurgeSongs(before time.Time, limit int) (int64, error) {
+	const op = "internal.database.postgres.PurgeSongs"
+	query := `DELETE FROM songs WHERE id IN (
+		SELECT id FROM songs WHERE deleted_at < $1 ORDER BY deleted_at, id LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`
+
+	result, err := d.Db.Exec(query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: exec %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected %w", op, err)
+	}
+	return rowsAffected, nil
+}
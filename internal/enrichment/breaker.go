@@ -0,0 +1,162 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/models"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen - запрос отклонён без обращения к внешнему API, т.к. автомат разомкнут
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// BreakerStatus - текущее состояние автомата для логов и эндпоинта статуса
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// Breaker - автоматический выключатель: после threshold неудачных запросов подряд
+// перестаёт обращаться к внешнему API на cooldown, затем пропускает один пробный запрос
+type Breaker struct {
+	log       *slog.Logger
+	next      Enricher
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(log *slog.Logger, next Enricher, cfg config.Enrichment) *Breaker {
+	threshold := cfg.BreakerThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{
+		log:       log,
+		next:      next,
+		threshold: threshold,
+		cooldown:  cfg.BreakerCooldown,
+		state:     StateClosed,
+	}
+}
+
+func (b *Breaker) SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error) {
+	const op = "internal.enrichment.Breaker.SongDetails"
+
+	if err := b.allow(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	details, err := b.next.SongDetails(ctx, group, song)
+	// Отмена запроса клиентом ничего не говорит о здоровье внешнего API
+	if err != nil && ctx.Err() != nil {
+		b.release()
+		return nil, err
+	}
+	b.record(err == nil || !errors.Is(err, ErrUnavailable))
+
+	return details, err
+}
+
+// Status возвращает снимок состояния автомата
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+
+	status := BreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.cooldown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+
+	return status
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+
+	switch b.state {
+	case StateOpen:
+		return fmt.Errorf("%w: %w", ErrUnavailable, ErrCircuitOpen)
+	case StateHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w: %w", ErrUnavailable, ErrCircuitOpen)
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// release снимает пометку пробного запроса, не меняя состояние
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if success {
+		if b.state != StateClosed {
+			b.transition(StateClosed)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+		b.openedAt = time.Now()
+		b.transition(StateOpen)
+	}
+}
+
+// advance переводит разомкнутый автомат в полуоткрытое состояние по истечении cooldown
+func (b *Breaker) advance() {
+	if b.state == StateOpen && !time.Now().Before(b.openedAt.Add(b.cooldown)) {
+		b.transition(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) transition(state string) {
+	b.log.Warn("circuit breaker state changed",
+		slog.String("from", b.state),
+		slog.String("to", state),
+		slog.Int("consecutive_failures", b.failures),
+	)
+	b.state = state
+}
@@ -0,0 +1,95 @@
+package enrichment_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/enrichment"
+	"song-lib/internal/models"
+	"testing"
+	"time"
+)
+
+// stubEnricher возвращает ошибки из списка по очереди, затем успешный ответ
+type stubEnricher struct {
+	errs  []error
+	calls int
+}
+
+func (s *stubEnricher) SongDetails(_ context.Context, _, _ string) (*models.SongDetails, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return &models.SongDetails{Text: "ok"}, nil
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// TestRetrier - повтор при недоступности и отказ от повтора при 404
+func TestRetrier(t *testing.T) {
+	cfg := config.Enrichment{RetryAttempts: 3, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 5 * time.Millisecond}
+
+	stub := &stubEnricher{errs: []error{enrichment.ErrUnavailable, enrichment.ErrUnavailable}}
+	details, err := enrichment.NewRetrier(discard, stub, cfg).SongDetails(context.Background(), "Muse", "Uprising")
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if details.Text != "ok" || stub.calls != 3 {
+		t.Errorf("expected 3 calls and details, got %d calls", stub.calls)
+	}
+
+	stub = &stubEnricher{errs: []error{enrichment.ErrNotFound}}
+	_, err = enrichment.NewRetrier(discard, stub, cfg).SongDetails(context.Background(), "Muse", "Uprising")
+	if !errors.Is(err, enrichment.ErrNotFound) || stub.calls != 1 {
+		t.Errorf("expected single call with not found, got %d calls and %v", stub.calls, err)
+	}
+
+	stub = &stubEnricher{errs: []error{enrichment.ErrUnavailable, enrichment.ErrUnavailable, enrichment.ErrUnavailable}}
+	_, err = enrichment.NewRetrier(discard, stub, cfg).SongDetails(context.Background(), "Muse", "Uprising")
+	if !errors.Is(err, enrichment.ErrUnavailable) || stub.calls != 3 {
+		t.Errorf("expected 3 calls and unavailable error, got %d calls and %v", stub.calls, err)
+	}
+}
+
+// TestBreaker - размыкание после порога, быстрый отказ и восстановление через cooldown
+func TestBreaker(t *testing.T) {
+	cfg := config.Enrichment{BreakerThreshold: 2, BreakerCooldown: 20 * time.Millisecond}
+	stub := &stubEnricher{errs: []error{enrichment.ErrUnavailable, enrichment.ErrUnavailable, enrichment.ErrUnavailable}}
+	breaker := enrichment.NewBreaker(discard, stub, cfg)
+
+	for i := 0; i < 2; i++ {
+		_, _ = breaker.SongDetails(context.Background(), "Muse", "Uprising")
+	}
+	if state := breaker.Status().State; state != enrichment.StateOpen {
+		t.Fatalf("expected state %s, got %s", enrichment.StateOpen, state)
+	}
+
+	_, err := breaker.SongDetails(context.Background(), "Muse", "Uprising")
+	if !errors.Is(err, enrichment.ErrCircuitOpen) || stub.calls != 2 {
+		t.Errorf("expected fast fail without call, got %d calls and %v", stub.calls, err)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if state := breaker.Status().State; state != enrichment.StateHalfOpen {
+		t.Fatalf("expected state %s, got %s", enrichment.StateHalfOpen, state)
+	}
+
+	// Пробный запрос неудачен - автомат снова размыкается
+	_, _ = breaker.SongDetails(context.Background(), "Muse", "Uprising")
+	if state := breaker.Status().State; state != enrichment.StateOpen {
+		t.Fatalf("expected state %s, got %s", enrichment.StateOpen, state)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if _, err := breaker.SongDetails(context.Background(), "Muse", "Uprising"); err != nil {
+		t.Fatalf("expected successful probe, got %v", err)
+	}
+	if status := breaker.Status(); status.State != enrichment.StateClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("expected closed breaker without failures, got %+v", status)
+	}
+}
@@ -0,0 +1,157 @@
+package enrichment
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache хранит ответы внешнего API. GetCachedDetails возвращает nil, если записи нет
+// или она устарела
+type Cache interface {
+	GetCachedDetails(key string) (*models.CachedSongDetails, error)
+	SetCachedDetails(key string, entry *models.CachedSongDetails) error
+}
+
+// Cached отдаёт детали песни из кэша и обращается к внешнему API только при промахе.
+// Ответ "не найдено" кэшируется на NegativeTTL, ошибки недоступности и неполные ответы не кэшируются
+type Cached struct {
+	log         *slog.Logger
+	next        Enricher
+	cache       Cache
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+func NewCached(log *slog.Logger, next Enricher, cache Cache, cfg config.Cache) *Cached {
+	return &Cached{
+		log:         log,
+		next:        next,
+		cache:       cache,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+	}
+}
+
+func (c *Cached) SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error) {
+	const op = "internal.enrichment.Cached.SongDetails"
+
+	log := c.log.With(slog.String("op", op))
+	key := CacheKey(group, song)
+
+	entry, err := c.cache.GetCachedDetails(key)
+	if err != nil {
+		log.Error("failed to read cache", "error", err)
+	}
+	if entry != nil {
+		if entry.NotFound {
+			return nil, fmt.Errorf("%s: cached: %w", op, ErrNotFound)
+		}
+		details := *entry.Details
+		return &details, nil
+	}
+
+	details, err := c.next.SongDetails(ctx, group, song)
+	switch {
+	case err == nil && !details.Partial:
+		cached := *details
+		c.store(log, key, &models.CachedSongDetails{Details: &cached, ExpiresAt: time.Now().Add(c.ttl)}, c.ttl)
+	case errors.Is(err, ErrNotFound):
+		c.store(log, key, &models.CachedSongDetails{NotFound: true, ExpiresAt: time.Now().Add(c.negativeTTL)}, c.negativeTTL)
+	}
+
+	return details, err
+}
+
+func (c *Cached) store(log *slog.Logger, key string, entry *models.CachedSongDetails, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	if err := c.cache.SetCachedDetails(key, entry); err != nil {
+		log.Error("failed to write cache", "error", err)
+	}
+}
+
+// CacheKey нормализует группу и название: регистр, пробелы по краям и повторяющиеся пробелы
+// не влияют на ключ
+func CacheKey(group, song string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), " "))
+	}
+
+	return normalize(group) + "\n" + normalize(song)
+}
+
+// MemoryCache - кэш в памяти с вытеснением давно не использованных записей (LRU)
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry models.CachedSongDetails
+}
+
+func NewMemoryCache(size int) *MemoryCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &MemoryCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (m *MemoryCache) GetCachedDetails(key string) (*models.CachedSongDetails, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := elem.Value.(*memoryItem)
+	if !time.Now().Before(item.entry.ExpiresAt) {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+		return nil, nil
+	}
+
+	m.order.MoveToFront(elem)
+	entry := item.entry
+	return &entry, nil
+}
+
+func (m *MemoryCache) SetCachedDetails(key string, entry *models.CachedSongDetails) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryItem).entry = *entry
+		m.order.MoveToFront(elem)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryItem{key: key, entry: *entry})
+
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryItem).key)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package enrichment_test
+
+import (
+	"context"
+	"errors"
+	"song-lib/internal/config"
+	"song-lib/internal/enrichment"
+	"song-lib/internal/models"
+	"testing"
+	"time"
+)
+
+// TestMemoryCache - вытеснение давно не использованных и устаревших записей
+func TestMemoryCache(t *testing.T) {
+	cache := enrichment.NewMemoryCache(2)
+	entry := func(ttl time.Duration) *models.CachedSongDetails {
+		return &models.CachedSongDetails{Details: &models.SongDetails{}, ExpiresAt: time.Now().Add(ttl)}
+	}
+
+	_ = cache.SetCachedDetails("a", entry(time.Minute))
+	_ = cache.SetCachedDetails("b", entry(time.Minute))
+	if got, _ := cache.GetCachedDetails("a"); got == nil {
+		t.Fatalf("expected entry a to be cached")
+	}
+
+	// b использовался раньше a и вытесняется первым
+	_ = cache.SetCachedDetails("c", entry(time.Minute))
+	if got, _ := cache.GetCachedDetails("b"); got != nil {
+		t.Errorf("expected entry b to be evicted")
+	}
+	if got, _ := cache.GetCachedDetails("a"); got == nil {
+		t.Errorf("expected entry a to stay cached")
+	}
+
+	_ = cache.SetCachedDetails("d", entry(-time.Second))
+	if got, _ := cache.GetCachedDetails("d"); got != nil {
+		t.Errorf("expected expired entry d to be missed")
+	}
+}
+
+// TestCached - повторные запросы и отрицательное кэширование не доходят до внешнего API
+func TestCached(t *testing.T) {
+	stub := &stubEnricher{errs: []error{enrichment.ErrUnavailable}}
+	cfg := config.Cache{TTL: time.Minute, NegativeTTL: time.Minute}
+	cached := enrichment.NewCached(discard, stub, enrichment.NewMemoryCache(10), cfg)
+
+	// Ошибки недоступности не кэшируются
+	if _, err := cached.SongDetails(context.Background(), "Muse", "Uprising"); !errors.Is(err, enrichment.ErrUnavailable) {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cached.SongDetails(context.Background(), " muse ", "UPRISING"); err != nil {
+			t.Fatalf("failed to get song details: %v", err)
+		}
+	}
+	if stub.calls != 2 {
+		t.Errorf("expected 2 calls to external api, got %d", stub.calls)
+	}
+
+	stub = &stubEnricher{errs: []error{enrichment.ErrNotFound}}
+	cached = enrichment.NewCached(discard, stub, enrichment.NewMemoryCache(10), cfg)
+	for i := 0; i < 3; i++ {
+		if _, err := cached.SongDetails(context.Background(), "Muse", "Unknown"); !errors.Is(err, enrichment.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	}
+	if stub.calls != 1 {
+		t.Errorf("expected 1 call to external api, got %d", stub.calls)
+	}
+}
+
+// TestCachedPartial - неполный ответ при недоступном источнике не кэшируется
+func TestCachedPartial(t *testing.T) {
+	cache := enrichment.NewMemoryCache(10)
+	merger := enrichment.NewMerger(discard,
+		stubProvider{name: "primary", details: &models.SongDetails{Text: "short"}},
+		stubProvider{name: "down", err: enrichment.ErrUnavailable},
+	)
+	cached := enrichment.NewCached(discard, merger, cache, config.Cache{TTL: time.Minute, NegativeTTL: time.Minute})
+
+	details, err := cached.SongDetails(context.Background(), "Muse", "Uprising")
+	if err != nil {
+		t.Fatalf("failed to get song details: %v", err)
+	}
+	if !details.Partial || details.Text != "short" {
+		t.Errorf("expected partial details, got %+v", details)
+	}
+	if entry, _ := cache.GetCachedDetails(enrichment.CacheKey("Muse", "Uprising")); entry != nil {
+		t.Errorf("expected partial details not to be cached")
+	}
+}
@@ -0,0 +1,106 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"song-lib/internal/config"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"strings"
+)
+
+var (
+	// ErrNotFound - внешнее API не знает такой песни
+	ErrNotFound error = errs.New(errs.ErrNotFound, "song details not found")
+	// ErrUnavailable - внешнее API недоступно (сеть, таймаут, 5xx)
+	ErrUnavailable error = errs.New(errs.ErrUpstream, "external api unavailable")
+	// ErrBadResponse - внешнее API ответило, но ответ не удалось разобрать
+	ErrBadResponse error = errs.New(errs.ErrUpstream, "bad response from external api")
+)
+
+// StatusError - неожиданный HTTP статус ответа внешнего API
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrUnavailable
+	default:
+		return ErrBadResponse
+	}
+}
+
+type Enricher interface {
+	SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error)
+}
+
+type Client struct {
+	baseURL    *url.URL
+	authHeader string
+	authToken  string
+	http       *http.Client
+}
+
+func New(cfg config.Enrichment) (Enricher, error) {
+	const op = "internal.enrichment.New"
+
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse base url: %w", op, err)
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("%s: base url %q must be absolute", op, cfg.BaseURL)
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		authHeader: cfg.AuthHeader,
+		authToken:  cfg.AuthToken,
+		http:       &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// SongDetails запрашивает у внешнего API дату выхода, текст и ссылку на песню
+func (c *Client) SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error) {
+	const op = "internal.enrichment.SongDetails"
+
+	u := c.baseURL.JoinPath("info")
+	u.RawQuery = url.Values{"group": {group}, "song": {song}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: new request: %w", op, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.authToken != "" && c.authHeader != "" {
+		req.Header.Set(c.authHeader, c.authToken)
+	}
+
+	response, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: do: %w: %w", op, ErrUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %w", op, &StatusError{StatusCode: response.StatusCode})
+	}
+
+	var details models.SongDetails
+	if err := json.NewDecoder(response.Body).Decode(&details); err != nil {
+		return nil, fmt.Errorf("%s: decode: %w: %w", op, ErrBadResponse, err)
+	}
+
+	return &details, nil
+}
@@ -0,0 +1,108 @@
+package enrichment_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"song-lib/internal/config"
+	"song-lib/internal/enrichment"
+	"testing"
+	"time"
+)
+
+// TestSongDetails - проверка кодирования параметров и заголовка авторизации
+func TestSongDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info" {
+			t.Errorf("expected path /info, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("group"); got != "AC/DC & Co" {
+			t.Errorf("expected group 'AC/DC & Co', got '%s'", got)
+		}
+		if got := r.URL.Query().Get("song"); got != "Back In Black" {
+			t.Errorf("expected song 'Back In Black', got '%s'", got)
+		}
+		if got := r.Header.Get("X-Api-Key"); got != "secret" {
+			t.Errorf("expected auth header 'secret', got '%s'", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"release_date":"25.07.1980","text":"Back in black","link":"https://example.com"}`))
+	}))
+	defer srv.Close()
+
+	client, err := enrichment.New(config.Enrichment{
+		BaseURL:    srv.URL + "/",
+		Timeout:    time.Second,
+		AuthHeader: "X-Api-Key",
+		AuthToken:  "secret",
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	details, err := client.SongDetails(context.Background(), "AC/DC & Co", "Back In Black")
+	if err != nil {
+		t.Fatalf("failed to get song details: %v", err)
+	}
+
+	if details.ReleaseDate != "25.07.1980" || details.Link != "https://example.com" {
+		t.Errorf("unexpected details: %+v", details)
+	}
+}
+
+// TestSongDetailsErrors - проверка типизированных ошибок клиента
+func TestSongDetailsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{name: "not found", status: http.StatusNotFound, want: enrichment.ErrNotFound},
+		{name: "server error", status: http.StatusBadGateway, want: enrichment.ErrUnavailable},
+		{name: "bad request", status: http.StatusBadRequest, want: enrichment.ErrBadResponse},
+		{name: "malformed json", status: http.StatusOK, body: "{", want: enrichment.ErrBadResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client, err := enrichment.New(config.Enrichment{BaseURL: srv.URL, Timeout: time.Second})
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			_, err = client.SongDetails(context.Background(), "Muse", "Uprising")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected error %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestSongDetailsContext - отмена контекста прерывает запрос
+func TestSongDetailsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	client, err := enrichment.New(config.Enrichment{BaseURL: srv.URL, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.SongDetails(ctx, "Muse", "Uprising")
+	if !errors.Is(err, enrichment.ErrUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected unavailable deadline error, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/models"
+	"strings"
+	"sync"
+)
+
+// DefaultProvider - имя источника, если ENRICHMENT_PROVIDERS не задан
+const DefaultProvider = "default"
+
+// Provider - источник деталей песни
+type Provider interface {
+	Name() string
+	SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error)
+}
+
+// ProviderStatus - состояние автомата отдельного источника
+type ProviderStatus struct {
+	Name    string        `json:"name"`
+	Breaker BreakerStatus `json:"breaker"`
+}
+
+// HTTPProvider - HTTP клиент источника с повторами и автоматическим выключателем
+type HTTPProvider struct {
+	name    string
+	breaker *Breaker
+}
+
+// NewProviders создаёт источники в порядке ENRICHMENT_PROVIDERS. Таймаут, авторизация,
+// повторы и настройки автомата общие, но у каждого источника свой автомат
+func NewProviders(log *slog.Logger, cfg config.Enrichment) ([]Provider, error) {
+	const op = "internal.enrichment.NewProviders"
+
+	entries := make([]string, 0, len(cfg.Providers))
+	for _, entry := range cfg.Providers {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		entries = append(entries, DefaultProvider+"="+cfg.BaseURL)
+	}
+
+	providers := make([]Provider, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name, baseURL, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s: provider %q must look like name=url", op, entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s: duplicate provider %q", op, name)
+		}
+		seen[name] = true
+
+		providerCfg := cfg
+		providerCfg.BaseURL = strings.TrimSpace(baseURL)
+		client, err := New(providerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: provider %s: %w", op, name, err)
+		}
+
+		providerLog := log.With(slog.String("provider", name))
+		providers = append(providers, &HTTPProvider{
+			name:    name,
+			breaker: NewBreaker(providerLog, NewRetrier(providerLog, client, providerCfg), providerCfg),
+		})
+	}
+
+	return providers, nil
+}
+
+func (p *HTTPProvider) Name() string {
+	return p.name
+}
+
+func (p *HTTPProvider) SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error) {
+	return p.breaker.SongDetails(ctx, group, song)
+}
+
+func (p *HTTPProvider) Status() BreakerStatus {
+	return p.breaker.Status()
+}
+
+// Merger опрашивает источники параллельно и объединяет ответы в порядке источников:
+// дата выхода, ссылка и альбом берутся из первого источника, где они не пусты, текст - самый длинный.
+// Если часть источников недоступна, ответ остальных помечается как неполный (SongDetails.Partial)
+type Merger struct {
+	log       *slog.Logger
+	providers []Provider
+}
+
+func NewMerger(log *slog.Logger, providers ...Provider) *Merger {
+	return &Merger{
+		log:       log,
+		providers: providers,
+	}
+}
+
+func (m *Merger) SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error) {
+	const op = "internal.enrichment.Merger.SongDetails"
+
+	type result struct {
+		details *models.SongDetails
+		err     error
+	}
+
+	results := make([]result, len(m.providers))
+	var wg sync.WaitGroup
+	for i, provider := range m.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			details, err := provider.SongDetails(ctx, group, song)
+			results[i] = result{details: details, err: err}
+		}()
+	}
+	wg.Wait()
+
+	merged := &models.SongDetails{Provenance: map[string]string{}}
+	var firstErr error
+	found := false
+	for i, res := range results {
+		name := m.providers[i].Name()
+		if res.err != nil {
+			if !errors.Is(res.err, ErrNotFound) {
+				m.log.Warn("provider failed", slog.String("op", op), slog.String("provider", name), "error", res.err)
+				if firstErr == nil {
+					firstErr = res.err
+				}
+			}
+			continue
+		}
+		found = true
+
+		if merged.ReleaseDate == "" && res.details.ReleaseDate != "" {
+			merged.ReleaseDate = res.details.ReleaseDate
+			merged.Provenance[models.FieldReleaseDate] = name
+		}
+		if merged.Link == "" && res.details.Link != "" {
+			merged.Link = res.details.Link
+			merged.Provenance[models.FieldLink] = name
+		}
+		if merged.Album == nil && res.details.Album != nil && res.details.Album.Title != "" {
+			merged.Album = res.details.Album
+			merged.Provenance[models.FieldAlbum] = name
+		}
+		if len(res.details.Text) > len(merged.Text) {
+			merged.Text = res.details.Text
+			merged.Provenance[models.FieldText] = name
+		}
+	}
+
+	if !found {
+		if firstErr != nil {
+			return nil, fmt.Errorf("%s: %w", op, firstErr)
+		}
+		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+	merged.Partial = firstErr != nil
+
+	return merged, nil
+}
+
+// Status возвращает состояние автоматов всех источников, у которых он есть
+func (m *Merger) Status() []ProviderStatus {
+	statuses := make([]ProviderStatus, 0, len(m.providers))
+	for _, provider := range m.providers {
+		if s, ok := provider.(interface{ Status() BreakerStatus }); ok {
+			statuses = append(statuses, ProviderStatus{Name: provider.Name(), Breaker: s.Status()})
+		}
+	}
+
+	return statuses
+}
@@ -0,0 +1,149 @@
+package enrichment_test
+
+import (
+	"context"
+	"errors"
+	"song-lib/internal/config"
+	"song-lib/internal/enrichment"
+	"song-lib/internal/fakeinfo"
+	"song-lib/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	name    string
+	details *models.SongDetails
+	err     error
+}
+
+func (p stubProvider) Name() string {
+	return p.name
+}
+
+func (p stubProvider) SongDetails(_ context.Context, _, _ string) (*models.SongDetails, error) {
+	return p.details, p.err
+}
+
+// TestMerger - правила объединения и источник каждого поля
+func TestMerger(t *testing.T) {
+	merger := enrichment.NewMerger(discard,
+		stubProvider{name: "primary", details: &models.SongDetails{ReleaseDate: "16.07.2006", Text: "short"}},
+		stubProvider{name: "down", err: enrichment.ErrUnavailable},
+		stubProvider{name: "lyrics", details: &models.SongDetails{ReleaseDate: "2006-07-16", Text: "much longer text", Link: "https://example.com"}},
+		stubProvider{name: "albums", details: &models.SongDetails{Album: &models.AlbumDetails{Title: "Black Holes and Revelations", TrackNumber: 2}}},
+	)
+
+	details, err := merger.SongDetails(context.Background(), "Muse", "Supermassive Black Hole")
+	if err != nil {
+		t.Fatalf("failed to merge song details: %v", err)
+	}
+
+	if details.ReleaseDate != "16.07.2006" || details.Text != "much longer text" || details.Link != "https://example.com" {
+		t.Errorf("unexpected merged details: %+v", details)
+	}
+	if details.Album == nil || details.Album.Title != "Black Holes and Revelations" || details.Album.TrackNumber != 2 {
+		t.Errorf("unexpected merged album: %+v", details.Album)
+	}
+	if !details.Partial {
+		t.Errorf("expected details to be partial while a provider is down")
+	}
+
+	expected := map[string]string{
+		models.FieldReleaseDate: "primary",
+		models.FieldText:        "lyrics",
+		models.FieldLink:        "lyrics",
+		models.FieldAlbum:       "albums",
+	}
+	for field, provider := range expected {
+		if details.Provenance[field] != provider {
+			t.Errorf("expected %s from %s, got %s", field, provider, details.Provenance[field])
+		}
+	}
+}
+
+// TestMergerErrors - ошибка недоступности важнее "не найдено"
+func TestMergerErrors(t *testing.T) {
+	merger := enrichment.NewMerger(discard,
+		stubProvider{name: "a", err: enrichment.ErrNotFound},
+		stubProvider{name: "b", err: enrichment.ErrNotFound},
+	)
+	if _, err := merger.SongDetails(context.Background(), "Muse", "Unknown"); !errors.Is(err, enrichment.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	merger = enrichment.NewMerger(discard,
+		stubProvider{name: "a", err: enrichment.ErrNotFound},
+		stubProvider{name: "b", err: enrichment.ErrUnavailable},
+	)
+	if _, err := merger.SongDetails(context.Background(), "Muse", "Unknown"); !errors.Is(err, enrichment.ErrUnavailable) {
+		t.Errorf("expected unavailable error, got %v", err)
+	}
+}
+
+// TestNewProviders - разбор списка источников из конфигурации
+func TestNewProviders(t *testing.T) {
+	providers, err := enrichment.NewProviders(discard, config.Enrichment{
+		Providers: []string{"main=http://localhost:8081", " backup = http://localhost:8082 ", ""},
+	})
+	if err != nil {
+		t.Fatalf("failed to create providers: %v", err)
+	}
+	if len(providers) != 2 || providers[0].Name() != "main" || providers[1].Name() != "backup" {
+		t.Errorf("unexpected providers: %v", providers)
+	}
+
+	providers, err = enrichment.NewProviders(discard, config.Enrichment{BaseURL: "http://localhost:8081"})
+	if err != nil || len(providers) != 1 || providers[0].Name() != enrichment.DefaultProvider {
+		t.Errorf("expected single default provider, got %v and %v", providers, err)
+	}
+
+	if _, err := enrichment.NewProviders(discard, config.Enrichment{Providers: []string{"a=http://x", "a=http://y"}}); err == nil {
+		t.Errorf("expected duplicate provider error")
+	}
+}
+
+// TestProvidersFakeInfo - полный путь запроса через повторы и объединение против заменителя внешнего API
+func TestProvidersFakeInfo(t *testing.T) {
+	srv, fake := fakeinfo.NewTestServer(discard, fakeinfo.DefaultFixtures(), fakeinfo.Options{FailFirst: 2})
+	defer srv.Close()
+
+	providers, err := enrichment.NewProviders(discard, config.Enrichment{
+		Providers:        []string{"fake=" + srv.URL},
+		Timeout:          time.Second,
+		RetryAttempts:    3,
+		RetryBaseDelay:   time.Millisecond,
+		RetryMaxDelay:    5 * time.Millisecond,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("failed to create providers: %v", err)
+	}
+	merger := enrichment.NewMerger(discard, providers...)
+
+	details, err := merger.SongDetails(context.Background(), "muse", "Supermassive Black Hole")
+	if err != nil {
+		t.Fatalf("failed to get song details: %v", err)
+	}
+	if details.ReleaseDate != "16.07.2006" || !strings.HasPrefix(details.Text, "Placeholder verse one") || details.Provenance[models.FieldText] != "fake" {
+		t.Errorf("unexpected details: %+v", details)
+	}
+	if details.Album == nil || details.Album.Title != "Black Holes and Revelations" {
+		t.Errorf("unexpected album: %+v", details.Album)
+	}
+	if fake.Requests() != 3 {
+		t.Errorf("expected 3 requests, got %d", fake.Requests())
+	}
+
+	fake.SetOptions(fakeinfo.Options{MalformedRate: 1})
+	if _, err := merger.SongDetails(context.Background(), "Muse", "Uprising"); !errors.Is(err, enrichment.ErrBadResponse) {
+		t.Errorf("expected bad response error, got %v", err)
+	}
+
+	fake.SetOptions(fakeinfo.Options{})
+	if _, err := merger.SongDetails(context.Background(), "Muse", "Unknown"); !errors.Is(err, enrichment.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
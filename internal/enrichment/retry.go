@@ -0,0 +1,84 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"song-lib/internal/config"
+	"song-lib/internal/models"
+	"time"
+)
+
+// Retrier повторяет запросы к внешнему API при недоступности (сеть, таймаут, 5xx)
+// с экспоненциальной задержкой и случайным разбросом (full jitter)
+type Retrier struct {
+	log       *slog.Logger
+	next      Enricher
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+func NewRetrier(log *slog.Logger, next Enricher, cfg config.Enrichment) *Retrier {
+	attempts := cfg.RetryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &Retrier{
+		log:       log,
+		next:      next,
+		attempts:  attempts,
+		baseDelay: cfg.RetryBaseDelay,
+		maxDelay:  cfg.RetryMaxDelay,
+	}
+}
+
+func (r *Retrier) SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error) {
+	const op = "internal.enrichment.Retrier.SongDetails"
+
+	var err error
+	for attempt := 0; attempt < r.attempts; attempt++ {
+		if attempt > 0 {
+			delay := r.backoff(attempt)
+			r.log.Warn("retrying external api request",
+				slog.String("op", op),
+				slog.Int("attempt", attempt+1),
+				slog.Duration("delay", delay),
+				slog.String("error", err.Error()),
+			)
+
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%s: %w: %w", op, ErrUnavailable, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		var details *models.SongDetails
+		details, err = r.next.SongDetails(ctx, group, song)
+		if err == nil {
+			return details, nil
+		}
+		if !errors.Is(err, ErrUnavailable) || ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %d attempts: %w", op, r.attempts, err)
+}
+
+// backoff возвращает случайную задержку в диапазоне [0, min(maxDelay, baseDelay*2^(attempt-1)))
+func (r *Retrier) backoff(attempt int) time.Duration {
+	ceiling := r.baseDelay << (attempt - 1)
+	if ceiling <= 0 || (r.maxDelay > 0 && ceiling > r.maxDelay) {
+		ceiling = r.maxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling)
+}
@@ -0,0 +1,200 @@
+// Package fakeinfo - заменитель внешнего API с информацией о песнях для локальной
+// разработки и тестов. Отдаёт /info из набора фикстур и умеет имитировать задержки,
+// ошибки и некорректный JSON
+package fakeinfo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"song-lib/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed fixtures.json
+var defaultFixtures []byte
+
+// Fixture - ответ внешнего API для пары группа + песня
+type Fixture struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+	// Album - необязательный альбом песни
+	Album *models.AlbumDetails `json:"album,omitempty"`
+}
+
+// Options - имитация неполадок внешнего API
+type Options struct {
+	// Latency - задержка перед каждым ответом
+	Latency time.Duration
+	// ErrorRate - доля запросов (0..1), на которые отвечает ErrorStatus
+	ErrorRate float64
+	// ErrorStatus - код ответа для имитируемых ошибок, по умолчанию 500
+	ErrorStatus int
+	// FailFirst - число первых запросов, которые гарантированно завершаются ошибкой
+	FailFirst int
+	// MalformedRate - доля запросов (0..1), на которые отдаётся некорректный JSON
+	MalformedRate float64
+	// AuthHeader и AuthToken - если заданы, запросы без токена получают 401
+	AuthHeader string
+	AuthToken  string
+}
+
+type Server struct {
+	log *slog.Logger
+
+	mu       sync.Mutex
+	fixtures map[string]models.SongDetails
+	opts     Options
+	requests int
+}
+
+// DefaultFixtures возвращает встроенный набор фикстур
+func DefaultFixtures() []Fixture {
+	var fixtures []Fixture
+	if err := json.Unmarshal(defaultFixtures, &fixtures); err != nil {
+		panic(fmt.Sprintf("fakeinfo: invalid embedded fixtures: %v", err))
+	}
+	return fixtures
+}
+
+// LoadFixtures читает фикстуры из JSON файла
+func LoadFixtures(path string) ([]Fixture, error) {
+	const op = "internal.fakeinfo.LoadFixtures"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read file: %w", op, err)
+	}
+
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("%s: unmarshal: %w", op, err)
+	}
+	return fixtures, nil
+}
+
+func New(log *slog.Logger, fixtures []Fixture, opts Options) *Server {
+	s := &Server{
+		log:      log,
+		fixtures: make(map[string]models.SongDetails, len(fixtures)),
+		opts:     opts,
+	}
+	for _, fixture := range fixtures {
+		s.fixtures[key(fixture.Group, fixture.Song)] = models.SongDetails{
+			ReleaseDate: fixture.ReleaseDate,
+			Text:        fixture.Text,
+			Link:        fixture.Link,
+			Album:       fixture.Album,
+		}
+	}
+
+	return s
+}
+
+// NewTestServer запускает сервер на случайном порту. Адрес - поле URL,
+// после использования сервер нужно закрыть
+func NewTestServer(log *slog.Logger, fixtures []Fixture, opts Options) (*httptest.Server, *Server) {
+	s := New(log, fixtures, opts)
+	return httptest.NewServer(s), s
+}
+
+// SetOptions меняет имитацию неполадок на лету и сбрасывает счётчик запросов
+func (s *Server) SetOptions(opts Options) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.opts = opts
+	s.requests = 0
+}
+
+// Requests возвращает число запросов к /info с последнего SetOptions
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/info" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests++
+	opts := s.opts
+	requests := s.requests
+	s.mu.Unlock()
+
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+	log := s.log.With(slog.String("group", group), slog.String("song", song))
+
+	if opts.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(opts.Latency):
+		}
+	}
+
+	if opts.AuthToken != "" && r.Header.Get(opts.AuthHeader) != opts.AuthToken {
+		log.Info("unauthorized request")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if requests <= opts.FailFirst || (opts.ErrorRate > 0 && rand.Float64() < opts.ErrorRate) {
+		status := opts.ErrorStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		log.Info("injected error", slog.Int("status", status))
+		w.WriteHeader(status)
+		return
+	}
+
+	if group == "" || song == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	details, ok := s.fixtures[key(group, song)]
+	s.mu.Unlock()
+	if !ok {
+		log.Info("song not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if opts.MalformedRate > 0 && rand.Float64() < opts.MalformedRate {
+		log.Info("injected malformed response")
+		_, _ = w.Write([]byte(`{"release_date": "16.07.2006", "text": `))
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(details)
+	log.Info("song details served")
+}
+
+func key(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\n" + strings.ToLower(strings.TrimSpace(song))
+}
@@ -0,0 +1,101 @@
+package fakeinfo_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"song-lib/internal/fakeinfo"
+	"song-lib/internal/models"
+	"strings"
+	"testing"
+)
+
+// get запрашивает /info тестового сервера для группы и песни
+func get(t *testing.T, baseURL, group, song string) *http.Response {
+	t.Helper()
+
+	res, err := http.Get(baseURL + "/info?" + url.Values{"group": {group}, "song": {song}}.Encode())
+	if err != nil {
+		t.Fatalf("failed to request details: %v", err)
+	}
+	t.Cleanup(func() { _ = res.Body.Close() })
+	return res
+}
+
+// TestDefaultFixtures - встроенные фикстуры содержат только тексты-заглушки
+func TestDefaultFixtures(t *testing.T) {
+	fixtures := fakeinfo.DefaultFixtures()
+	if len(fixtures) == 0 {
+		t.Fatal("expected embedded fixtures")
+	}
+	for _, fixture := range fixtures {
+		if fixture.Group == "" || fixture.Song == "" || !strings.HasPrefix(fixture.Text, "Placeholder verse one of "+fixture.Song) {
+			t.Errorf("unexpected fixture: %+v", fixture)
+		}
+	}
+}
+
+// TestServerFixture - поиск фикстуры без учёта регистра и пробелов по краям
+func TestServerFixture(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv, _ := fakeinfo.NewTestServer(log, fakeinfo.DefaultFixtures(), fakeinfo.Options{})
+	defer srv.Close()
+
+	res := get(t, srv.URL, " muse ", "SUPERMASSIVE black hole")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var details models.SongDetails
+	if err := json.NewDecoder(res.Body).Decode(&details); err != nil {
+		t.Fatalf("failed to decode details: %v", err)
+	}
+	if details.ReleaseDate != "16.07.2006" || !strings.HasPrefix(details.Text, "Placeholder verse one of Supermassive Black Hole") {
+		t.Errorf("unexpected details: %+v", details)
+	}
+	if details.Album == nil || details.Album.Title != "Black Holes and Revelations" || details.Album.TrackNumber != 2 {
+		t.Errorf("unexpected album: %+v", details.Album)
+	}
+}
+
+// TestServerNotFound - неизвестная песня и запрос без песни
+func TestServerNotFound(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv, s := fakeinfo.NewTestServer(log, fakeinfo.DefaultFixtures(), fakeinfo.Options{})
+	defer srv.Close()
+
+	if res := get(t, srv.URL, "Muse", "Unknown Song"); res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", res.StatusCode)
+	}
+	if res := get(t, srv.URL, "Muse", ""); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", res.StatusCode)
+	}
+	if s.Requests() != 2 {
+		t.Errorf("expected 2 requests, got %d", s.Requests())
+	}
+}
+
+// TestLoadFixtures - фикстуры из файла и ошибка для отсутствующего файла
+func TestLoadFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	data := `[{"group": "Local", "song": "Demo", "release_date": "2024-01-01", "text": "Placeholder verse one of Demo"}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write fixtures: %v", err)
+	}
+
+	fixtures, err := fakeinfo.LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	if len(fixtures) != 1 || fixtures[0].Group != "Local" || fixtures[0].Song != "Demo" {
+		t.Errorf("unexpected fixtures: %+v", fixtures)
+	}
+
+	if _, err := fakeinfo.LoadFixtures(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,22 @@
+package author
+
+import (
+	"fmt"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"strings"
+	"unicode/utf8"
+)
+
+// Header - заголовок запроса с автором изменения, автор сохраняется в ревизии песни
+const Header = "X-Author"
+
+// FromRequest возвращает автора изменения без пробелов по краям. Без заголовка автор пустой
+func FromRequest(r *http.Request) (string, error) {
+	author := strings.TrimSpace(r.Header.Get(Header))
+	if utf8.RuneCountInString(author) > models.MaxAuthorLength {
+		return "", errs.New(errs.ErrValidation, fmt.Sprintf("%s must be at most %d characters long", Header, models.MaxAuthorLength))
+	}
+	return author, nil
+}
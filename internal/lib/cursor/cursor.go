@@ -0,0 +1,79 @@
+// Package cursor кодирует позицию в списке песен в непрозрачную для клиента строку
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"strings"
+)
+
+var (
+	// ErrInvalid - курсор повреждён или создан не этим сервисом
+	ErrInvalid error = errs.New(errs.ErrValidation, "invalid cursor")
+	// ErrSortMismatch - курсор получен при другой сортировке
+	ErrSortMismatch error = errs.New(errs.ErrValidation, "cursor was issued for a different sort order")
+)
+
+type payload struct {
+	Sort     string    `json:"s"`
+	Values   []*string `json:"v"`
+	ID       int64     `json:"id"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// Encode возвращает курсор в виде строки, для nil - пустую строку
+func Encode(sort []models.SortField, c *models.SongCursor) string {
+	if c == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(payload{
+		Sort:     FormatSort(sort),
+		Values:   c.Values,
+		ID:       c.ID,
+		Backward: c.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode разбирает курсор и проверяет, что он выдан для той же сортировки. Пустая строка - nil
+func Decode(token string, sort []models.SortField) (*models.SongCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil || p.ID <= 0 {
+		return nil, ErrInvalid
+	}
+	if p.Sort != FormatSort(sort) {
+		return nil, ErrSortMismatch
+	}
+
+	return &models.SongCursor{
+		Values:   p.Values,
+		ID:       p.ID,
+		Backward: p.Backward,
+	}, nil
+}
+
+// FormatSort записывает сортировку в том же виде, что и параметр sort
+func FormatSort(sort []models.SortField) string {
+	fields := make([]string, 0, len(sort))
+	for _, field := range sort {
+		if field.Desc {
+			fields = append(fields, "-"+field.Field)
+			continue
+		}
+		fields = append(fields, field.Field)
+	}
+
+	return strings.Join(fields, ",")
+}
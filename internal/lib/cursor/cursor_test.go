@@ -0,0 +1,44 @@
+package cursor
+
+import (
+	"errors"
+	"reflect"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	sort := []models.SortField{{Field: models.SortReleaseDate, Desc: true}, {Field: models.SortName}}
+	name := "Creep"
+	c := &models.SongCursor{Values: []*string{nil, &name}, ID: 42, Backward: true}
+
+	token := Encode(sort, c)
+	decoded, err := Decode(token, sort)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, c) {
+		t.Errorf("expected %+v, got %+v", c, decoded)
+	}
+
+	// Курсор другой сортировки отклоняется
+	if _, err := Decode(token, sort[1:]); !errors.Is(err, ErrSortMismatch) {
+		t.Errorf("expected sort mismatch, got %v", err)
+	}
+
+	if Encode(sort, nil) != "" {
+		t.Error("expected empty token for nil cursor")
+	}
+	if decoded, err := Decode("", sort); decoded != nil || err != nil {
+		t.Errorf("expected nil cursor for empty token, got %+v, %v", decoded, err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm90IGpzb24", Encode(nil, &models.SongCursor{})} {
+		if _, err := Decode(token, nil); !errors.Is(err, errs.ErrValidation) {
+			t.Errorf("token %q: expected validation error, got %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"song-lib/internal/models"
+	"strings"
+)
+
+// MaxCells ограничивает размер таблицы LCS (строки старого текста на строки нового), таблица
+// занимает не больше 1 МБ. Если тексты больше, старый текст целиком удаляется, а новый добавляется
+const MaxCells = 250_000
+
+// Lines сравнивает тексты построчно и возвращает кратчайший набор удалённых и добавленных строк
+// (наибольшая общая подпоследовательность). Удалённые строки идут перед добавленными на их месте
+func Lines(from, to string) []models.DiffLine {
+	a, b := split(from), split(to)
+
+	// Общие начало и конец не участвуют в поиске, чтобы небольшие правки больших текстов были дешёвыми
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]models.DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: line})
+	}
+	lines = append(lines, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: line})
+	}
+	return lines
+}
+
+// middle сравнивает строки без общих начала и конца
+func middle(a, b []string) []models.DiffLine {
+	if len(a)*len(b) > MaxCells {
+		return replace(a, b)
+	}
+
+	// lcs[i][j] - длина наибольшей общей подпоследовательности a[i:] и b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+				lcs[i*width+j] = lcs[(i+1)*width+j]
+			default:
+				lcs[i*width+j] = lcs[i*width+j+1]
+			}
+		}
+	}
+
+	var lines []models.DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			lines = append(lines, models.DiffLine{Op: models.DiffDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, models.DiffLine{Op: models.DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	return append(lines, replace(a[i:], b[j:])...)
+}
+
+func replace(a, b []string) []models.DiffLine {
+	lines := make([]models.DiffLine, 0, len(a)+len(b))
+	for _, line := range a {
+		lines = append(lines, models.DiffLine{Op: models.DiffDelete, Text: line})
+	}
+	for _, line := range b {
+		lines = append(lines, models.DiffLine{Op: models.DiffInsert, Text: line})
+	}
+	return lines
+}
+
+// split делит текст на строки. Пустой текст не содержит строк, \r\n считается одним переводом строки
+func split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
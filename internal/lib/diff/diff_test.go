@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"song-lib/internal/models"
+	"strings"
+	"testing"
+)
+
+// TestLines - построчные различия небольших текстов
+func TestLines(t *testing.T) {
+	eq := func(text string) models.DiffLine { return models.DiffLine{Op: models.DiffEqual, Text: text} }
+	ins := func(text string) models.DiffLine { return models.DiffLine{Op: models.DiffInsert, Text: text} }
+	del := func(text string) models.DiffLine { return models.DiffLine{Op: models.DiffDelete, Text: text} }
+
+	tests := []struct {
+		name     string
+		from, to string
+		expected []models.DiffLine
+	}{
+		{name: "both empty", from: "", to: "", expected: []models.DiffLine{}},
+		{name: "equal", from: "a\nb", to: "a\nb", expected: []models.DiffLine{eq("a"), eq("b")}},
+		{name: "added text", from: "", to: "a\nb", expected: []models.DiffLine{ins("a"), ins("b")}},
+		{name: "removed text", from: "a\nb", to: "", expected: []models.DiffLine{del("a"), del("b")}},
+		{name: "changed line", from: "a\nb\nc", to: "a\nB\nc", expected: []models.DiffLine{eq("a"), del("b"), ins("B"), eq("c")}},
+		{name: "inserted line", from: "a\nc", to: "a\nb\nc", expected: []models.DiffLine{eq("a"), ins("b"), eq("c")}},
+		{
+			name:     "moved line",
+			from:     "a\nb\nc\nd",
+			to:       "b\nc\na\nd",
+			expected: []models.DiffLine{del("a"), eq("b"), eq("c"), ins("a"), eq("d")},
+		},
+		{name: "windows line endings", from: "a\r\nb", to: "a\nb", expected: []models.DiffLine{eq("a"), eq("b")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.from, tt.to)
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, lines)
+			}
+		})
+	}
+}
+
+// TestLinesLarge - тексты больше MaxCells заменяются целиком, но общие начало и конец сохраняются
+func TestLinesLarge(t *testing.T) {
+	a := strings.Repeat("a\n", 3000) + "x"
+	b := "head\n" + strings.Repeat("b\n", 3000) + "x"
+
+	lines := Lines(a, b)
+	if len(lines) != 6002 {
+		t.Fatalf("expected 6002 lines, got %d", len(lines))
+	}
+	if lines[0] != (models.DiffLine{Op: models.DiffDelete, Text: "a"}) {
+		t.Errorf("expected deleted first line, got %+v", lines[0])
+	}
+	if last := lines[len(lines)-1]; last != (models.DiffLine{Op: models.DiffEqual, Text: "x"}) {
+		t.Errorf("expected common last line, got %+v", last)
+	}
+}
+
+// TestLinesMaxCells - таблица ровно в MaxCells ещё строится, на строку больше - тексты заменяются целиком
+func TestLinesMaxCells(t *testing.T) {
+	text := func(prefix string, n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return lines
+	}
+	hasEqual := func(lines []models.DiffLine) bool {
+		return slices.ContainsFunc(lines, func(line models.DiffLine) bool { return line.Op == models.DiffEqual })
+	}
+
+	// Общая строка в середине, первые и последние строки различаются
+	a := text("a", 500)
+	b := text("b", MaxCells/len(a))
+	b[len(b)/2] = a[len(a)/2]
+
+	if lines := Lines(strings.Join(a, "\n"), strings.Join(b, "\n")); !hasEqual(lines) {
+		t.Errorf("expected common line at %d cells", len(a)*len(b))
+	}
+
+	b = append(b, "extra")
+	if lines := Lines(strings.Join(a, "\n"), strings.Join(b, "\n")); hasEqual(lines) || len(lines) != len(a)+len(b) {
+		t.Errorf("expected full replacement at %d cells, got %d lines", len(a)*len(b), len(lines))
+	}
+}
@@ -0,0 +1,43 @@
+package errs
+
+import "errors"
+
+// Виды ошибок сервиса. Транспортный слой определяет по ним HTTP статус
+var (
+	ErrValidation = errors.New("validation failed")
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrUpstream   = errors.New("upstream failure")
+	ErrTimeout    = errors.New("timeout")
+)
+
+// Error - ошибка определённого вида с сообщением, которое можно показать клиенту
+type Error struct {
+	Kind  error
+	Msg   string
+	Cause error
+}
+
+// New создаёт ошибку вида kind с сообщением msg
+func New(kind error, msg string) *Error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+// Wrap создаёт ошибку вида kind с сообщением msg, сохраняя исходную причину для логов
+func Wrap(kind error, msg string, cause error) *Error {
+	return &Error{Kind: kind, Msg: msg, Cause: cause}
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Msg + ": " + e.Cause.Error()
+	}
+	return e.Msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Kind, e.Cause}
+	}
+	return []error{e.Kind}
+}
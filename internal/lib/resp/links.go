@@ -0,0 +1,45 @@
+package resp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Link - ссылка на связанную страницу для заголовка Link (RFC 8288)
+type Link struct {
+	Rel string
+	URL string
+}
+
+// PageURL возвращает путь и параметры текущего запроса с заменой параметров из set.
+// Пустое значение удаляет параметр
+func PageURL(r *http.Request, set map[string]string) string {
+	query := r.URL.Query()
+	for key, value := range set {
+		if value == "" {
+			query.Del(key)
+			continue
+		}
+		query.Set(key, value)
+	}
+
+	if len(query) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// SetLinks записывает заголовок Link для ссылок с непустым адресом
+func SetLinks(w http.ResponseWriter, links ...Link) {
+	values := make([]string, 0, len(links))
+	for _, link := range links {
+		if link.URL != "" {
+			values = append(values, fmt.Sprintf("<%s>; rel=%q", link.URL, link.Rel))
+		}
+	}
+
+	if len(values) > 0 {
+		w.Header().Set("Link", strings.Join(values, ", "))
+	}
+}
@@ -0,0 +1,35 @@
+package resp
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPageURL(t *testing.T) {
+	r := httptest.NewRequest("GET", "/songs?group=muse&page=3&limit=5", nil)
+
+	got := PageURL(r, map[string]string{"cursor": "abc", "page": ""})
+	if expected := "/songs?cursor=abc&group=muse&limit=5"; got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	r = httptest.NewRequest("GET", "/songs?page=2", nil)
+	if got := PageURL(r, map[string]string{"page": ""}); got != "/songs" {
+		t.Errorf("expected /songs, got %s", got)
+	}
+}
+
+func TestSetLinks(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetLinks(w, Link{Rel: "next", URL: "/songs?cursor=a"}, Link{Rel: "prev"})
+
+	if got, expected := w.Header().Get("Link"), `</songs?cursor=a>; rel="next"`; got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	w = httptest.NewRecorder()
+	SetLinks(w, Link{Rel: "next"})
+	if _, ok := w.Header()["Link"]; ok {
+		t.Error("expected no Link header without urls")
+	}
+}
@@ -0,0 +1,50 @@
+package resp
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+	"song-lib/internal/lib/errs"
+	"strconv"
+)
+
+// Размер страницы списков по умолчанию и наибольший размер страницы
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Pagination берёт номер страницы и её размер: по умолчанию DefaultLimit, не больше MaxLimit
+func Pagination(values url.Values) (int, int) {
+	return Page(values), Limit(values, DefaultLimit, MaxLimit)
+}
+
+// Page берёт номер страницы, отсутствующий или некорректный номер - первая страница
+func Page(values url.Values) int {
+	if p := values.Get("page"); p != "" {
+		if pInt, err := strconv.Atoi(p); err == nil && pInt > 0 {
+			return pInt
+		}
+	}
+	return 1
+}
+
+// Limit берёт размер страницы: defaultLimit, если он не задан или некорректен, и не больше maxLimit
+func Limit(values url.Values, defaultLimit, maxLimit int) int {
+	if l := values.Get("limit"); l != "" {
+		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
+			return min(lInt, maxLimit)
+		}
+	}
+	return defaultLimit
+}
+
+// PathID берёт положительный идентификатор из параметра пути param. Ошибка валидации
+// называет его name: "invalid <name>"
+func PathID(r *http.Request, param, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errs.New(errs.ErrValidation, "invalid "+name)
+	}
+	return id, nil
+}
@@ -0,0 +1,56 @@
+package resp
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http/httptest"
+	"net/url"
+	"song-lib/internal/lib/errs"
+	"testing"
+)
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		query string
+		page  int
+		limit int
+	}{
+		{query: "", page: 1, limit: DefaultLimit},
+		{query: "page=3&limit=25", page: 3, limit: 25},
+		{query: "page=0&limit=-5", page: 1, limit: DefaultLimit},
+		{query: "page=two&limit=ten", page: 1, limit: DefaultLimit},
+		{query: "limit=100000", page: 1, limit: MaxLimit},
+	}
+
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		page, limit := Pagination(values)
+		if page != tt.page || limit != tt.limit {
+			t.Errorf("%q: expected page %d and limit %d, got %d and %d", tt.query, tt.page, tt.limit, page, limit)
+		}
+	}
+
+	values := url.Values{"limit": {"500"}}
+	if got := Limit(values, 50, 200); got != 200 {
+		t.Errorf("expected limit 200, got %d", got)
+	}
+}
+
+func TestPathID(t *testing.T) {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "42")
+	rctx.URLParams.Add("song_id", "-1")
+	r := httptest.NewRequest("GET", "/albums/42/tracks/-1", nil)
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	if id, err := PathID(r, "id", "album id"); err != nil || id != 42 {
+		t.Errorf("expected id 42, got %d, %v", id, err)
+	}
+
+	_, err := PathID(r, "song_id", "song_id")
+	var appErr *errs.Error
+	if !errors.Is(err, errs.ErrValidation) || !errors.As(err, &appErr) || appErr.Msg != "invalid song_id" {
+		t.Errorf("expected validation error invalid song_id, got %v", err)
+	}
+}
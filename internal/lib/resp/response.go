@@ -1,14 +1,20 @@
 package resp
 
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/validation"
+)
+
 type Response struct {
 	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
 }
 
-const (
-	StatusOK    = "OK"
-	StatusError = "Error"
-)
+const StatusOK = "OK"
 
 func OK() Response {
 	return Response{
@@ -16,9 +22,120 @@ func OK() Response {
 	}
 }
 
-func Error(msg string) Response {
-	return Response{
-		Status: StatusError,
-		Error:  msg,
+// ContentTypeProblem - тип содержимого ответов с ошибкой (RFC 7807)
+const ContentTypeProblem = "application/problem+json"
+
+// Типы проблем (поле type). Относительные URI разрешаются относительно адреса запроса
+const (
+	TypeValidation = "/problems/validation-error"
+	TypeNotFound   = "/problems/not-found"
+	TypeConflict   = "/problems/conflict"
+	TypeUpstream   = "/problems/upstream-failure"
+	TypeTimeout    = "/problems/timeout"
+	TypeInternal   = "/problems/internal-error"
+)
+
+// Problem - описание ошибки в формате RFC 7807
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError - ошибка проверки отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// NewProblem создаёт описание ошибки без указания вида (type about:blank).
+// Instance - идентификатор запроса
+func NewProblem(r *http.Request, status int, detail string) Problem {
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: middleware.GetReqID(r.Context()),
+	}
+}
+
+// WriteProblem отправляет описание ошибки с типом содержимого application/problem+json
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// StatusCode возвращает HTTP статус для вида ошибки сервиса. Таймаут проверяется раньше
+// ErrUpstream: ошибка внешнего API, не успевшего ответить, может быть обоих видов
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, errs.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errs.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, errs.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, errs.ErrUpstream):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Fail отвечает ошибкой со статусом и типом по виду err. В detail попадает сообщение
+// ошибки сервиса (errs.Error), а для прочих ошибок - msg. Ошибки валидатора
+// перечисляются по полям в errors
+func Fail(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var appErr *errs.Error
+	if errors.As(err, &appErr) {
+		msg = appErr.Msg
+	}
+
+	p := NewProblem(r, StatusCode(err), msg)
+	p.Type, p.Title = problemType(err)
+	p.Errors = fieldErrors(err)
+
+	WriteProblem(w, p)
+}
+
+func problemType(err error) (string, string) {
+	switch {
+	case errors.Is(err, errs.ErrValidation):
+		return TypeValidation, "Validation failed"
+	case errors.Is(err, errs.ErrNotFound):
+		return TypeNotFound, "Resource not found"
+	case errors.Is(err, errs.ErrConflict):
+		return TypeConflict, "Conflict"
+	case errors.Is(err, errs.ErrTimeout):
+		return TypeTimeout, "External API timeout"
+	case errors.Is(err, errs.ErrUpstream):
+		return TypeUpstream, "External API failure"
+	default:
+		return TypeInternal, "Internal server error"
+	}
+}
+
+func fieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: validation.Message(fe),
+		})
 	}
+	return fields
 }
@@ -0,0 +1,82 @@
+package resp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"net/http/httptest"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"testing"
+)
+
+// fail вызывает resp.Fail внутри middleware.RequestID и возвращает разобранный ответ
+func fail(t *testing.T, err error) (*httptest.ResponseRecorder, resp.Problem) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/songs/1", nil)
+	r.Header.Set(middleware.RequestIDHeader, "req-42")
+
+	middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp.Fail(w, r, err, "fallback")
+	})).ServeHTTP(w, r)
+
+	var problem resp.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return w, problem
+}
+
+// TestFail - статус, тип и текст ошибки по её виду
+func TestFail(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		typ    string
+		detail string
+	}{
+		{name: "validation", err: errs.ErrValidation, status: http.StatusBadRequest, typ: resp.TypeValidation, detail: "fallback"},
+		{name: "not found", err: fmt.Errorf("op: %w", errs.New(errs.ErrNotFound, "song not found")), status: http.StatusNotFound, typ: resp.TypeNotFound, detail: "song not found"},
+		{name: "conflict", err: errs.Wrap(errs.ErrConflict, "already exists", errors.New("pq: duplicate key")), status: http.StatusConflict, typ: resp.TypeConflict, detail: "already exists"},
+		{name: "upstream", err: errs.New(errs.ErrUpstream, "external api unavailable"), status: http.StatusBadGateway, typ: resp.TypeUpstream, detail: "external api unavailable"},
+		{name: "timeout", err: errs.Wrap(errs.ErrTimeout, "external api timed out", errs.New(errs.ErrUpstream, "external api unavailable")), status: http.StatusGatewayTimeout, typ: resp.TypeTimeout, detail: "external api timed out"},
+		{name: "internal", err: errors.New("connection refused"), status: http.StatusInternalServerError, typ: resp.TypeInternal, detail: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := fail(t, tt.err)
+
+			if w.Code != tt.status || problem.Status != tt.status {
+				t.Errorf("expected status %d, got %d and %d", tt.status, w.Code, problem.Status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != resp.ContentTypeProblem {
+				t.Errorf("expected content type %s, got %s", resp.ContentTypeProblem, ct)
+			}
+			if problem.Type != tt.typ || problem.Detail != tt.detail || problem.Instance != "req-42" {
+				t.Errorf("unexpected problem: %+v", problem)
+			}
+		})
+	}
+}
+
+// TestFailValidation - ошибки валидатора перечисляются по полям
+func TestFailValidation(t *testing.T) {
+	req := struct {
+		Group string `validate:"required"`
+		Song  string `validate:"required"`
+	}{Song: "Uprising"}
+
+	err := validator.New().Struct(req)
+	_, problem := fail(t, errs.Wrap(errs.ErrValidation, "invalid request", err))
+
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "Group" || problem.Errors[0].Rule != "required" {
+		t.Errorf("unexpected field errors: %+v", problem.Errors)
+	}
+}
@@ -0,0 +1,138 @@
+// Package search разбирает язык запросов параметра q списка песен:
+//
+//	muse "black hole" name:creep -live group:"the beatles"
+//
+// Слова и фразы в кавычках ищутся без учёта регистра как подстрока в группе, названии
+// и тексте песни. Префикс поля (group:, name: или song:, text:, link:) ограничивает поиск
+// одним полем, минус в начале исключает совпадения. Все условия объединяются через И.
+// Там же разбирается параметр сортировки sort
+package search
+
+import (
+	"fmt"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения запроса: число условий и длина значения в символах
+const (
+	MaxTerms       = 10
+	MaxValueLength = 255
+)
+
+var fields = map[string]string{
+	"group": models.FieldGroup,
+	"name":  models.FieldName,
+	"song":  models.FieldName,
+	"text":  models.FieldText,
+	"link":  models.FieldLink,
+}
+
+// Parse разбирает строку запроса. Ошибки синтаксиса - ошибки валидации
+func Parse(q string) ([]models.SearchTerm, error) {
+	var terms []models.SearchTerm
+
+	rest := strings.TrimSpace(q)
+	for rest != "" {
+		term, tail, err := next(rest)
+		if err != nil {
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("invalid query: %s", err))
+		}
+		rest = strings.TrimLeftFunc(tail, unicode.IsSpace)
+
+		if term.Value == "" {
+			continue
+		}
+		if utf8.RuneCountInString(term.Value) > MaxValueLength {
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("invalid query: term longer than %d characters", MaxValueLength))
+		}
+		terms = append(terms, term)
+		if len(terms) > MaxTerms {
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("invalid query: more than %d terms", MaxTerms))
+		}
+	}
+
+	return terms, nil
+}
+
+// next читает одно условие в начале s и возвращает остаток строки
+func next(s string) (models.SearchTerm, string, error) {
+	var term models.SearchTerm
+
+	if strings.HasPrefix(s, "-") {
+		term.Negate = true
+		s = s[1:]
+	}
+
+	if prefix, value, ok := strings.Cut(s, ":"); ok && !strings.ContainsFunc(prefix, isDelimiter) {
+		field, known := fields[strings.ToLower(prefix)]
+		if !known {
+			return term, "", fmt.Errorf("unknown field %q", prefix)
+		}
+		term.Field = field
+		s = value
+	}
+
+	if strings.HasPrefix(s, `"`) {
+		end := strings.IndexByte(s[1:], '"')
+		if end < 0 {
+			return term, "", fmt.Errorf("unterminated quote")
+		}
+		term.Value = strings.TrimSpace(s[1 : end+1])
+		return term, s[end+2:], nil
+	}
+
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end < 0 {
+		end = len(s)
+	}
+	term.Value = s[:end]
+	if strings.Contains(term.Value, `"`) {
+		return term, "", fmt.Errorf("unexpected quote in %q", term.Value)
+	}
+
+	return term, s[end:], nil
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '"'
+}
+
+var sortFields = map[string]bool{
+	models.SortID:          true,
+	models.SortGroup:       true,
+	models.SortName:        true,
+	models.SortReleaseDate: true,
+}
+
+// ParseSort разбирает параметр sort: поля через запятую, минус в начале - по убыванию,
+// например -release_date,group,name
+func ParseSort(s string) ([]models.SortField, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	sort := make([]models.SortField, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		field := models.SortField{Field: strings.ToLower(strings.TrimSpace(part))}
+		if rest, ok := strings.CutPrefix(field.Field, "-"); ok {
+			field.Field, field.Desc = rest, true
+		}
+
+		if !sortFields[field.Field] {
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("invalid sort: unknown field %q, allowed: id, group, name, release_date", field.Field))
+		}
+		if seen[field.Field] {
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("invalid sort: duplicate field %q", field.Field))
+		}
+		seen[field.Field] = true
+		sort = append(sort, field)
+	}
+
+	return sort, nil
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []models.SearchTerm
+	}{
+		{name: "empty", query: "   ", expected: nil},
+		{name: "words", query: "muse  hole", expected: []models.SearchTerm{{Value: "muse"}, {Value: "hole"}}},
+		{name: "phrase", query: `"black hole"`, expected: []models.SearchTerm{{Value: "black hole"}}},
+		{
+			name:     "fields and negation",
+			query:    `song:creep -live GROUP:"the beatles"`,
+			expected: []models.SearchTerm{{Field: models.FieldName, Value: "creep"}, {Value: "live", Negate: true}, {Field: models.FieldGroup, Value: "the beatles"}},
+		},
+		{name: "colon in value", query: "link:https://example.com", expected: []models.SearchTerm{{Field: models.FieldLink, Value: "https://example.com"}}},
+		{name: "empty terms are skipped", query: `- text:""`, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(terms, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, terms)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	queries := []string{
+		`"black hole`,
+		`year:2006`,
+		`bla"ck`,
+		strings.Repeat("a ", MaxTerms+1),
+		strings.Repeat("a", MaxValueLength+1),
+	}
+
+	for _, query := range queries {
+		if _, err := Parse(query); !errors.Is(err, errs.ErrValidation) {
+			t.Errorf("query %.20q: expected validation error, got %v", query, err)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort(" -release_date, Group ,name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []models.SortField{
+		{Field: models.SortReleaseDate, Desc: true},
+		{Field: models.SortGroup},
+		{Field: models.SortName},
+	}
+	if !reflect.DeepEqual(sort, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sort)
+	}
+
+	for _, s := range []string{"text", "name,-name", "name,,id"} {
+		if _, err := ParseSort(s); !errors.Is(err, errs.ErrValidation) {
+			t.Errorf("sort %q: expected validation error, got %v", s, err)
+		}
+	}
+}
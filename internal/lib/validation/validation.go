@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"song-lib/internal/lib/errs"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReleaseDateLayouts - допустимые форматы даты выхода: ISO 8601 и формат внешнего API
+var ReleaseDateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+var (
+	once     sync.Once
+	instance *validator.Validate
+)
+
+// Validator возвращает общий экземпляр валидатора. Пользовательские правила регистрируются
+// один раз, в ошибках поля называются так же, как в JSON
+func Validator() *validator.Validate {
+	once.Do(func() {
+		v := validator.New(validator.WithRequiredStructEnabled())
+
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		// Ошибки регистрации возможны только при пустом имени правила
+		_ = v.RegisterValidation("notblank", notBlank)
+		_ = v.RegisterValidation("songdate", songDate)
+
+		instance = v
+	})
+
+	return instance
+}
+
+// Struct проверяет запрос и возвращает ошибку валидации с перечнем полей
+func Struct(req any) error {
+	if err := Validator().Struct(req); err != nil {
+		return errs.Wrap(errs.ErrValidation, "invalid request", err)
+	}
+	return nil
+}
+
+// limitMessage описывает ограничение min или max: у чисел - значение, у списков - число элементов,
+// у строк - длину
+func limitMessage(fe validator.FieldError, bound string) string {
+	switch kind := fe.Kind(); {
+	case kind >= reflect.Int && kind <= reflect.Float64:
+		return fmt.Sprintf("must be %s %s", bound, fe.Param())
+	case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+		return fmt.Sprintf("must contain %s %s items", bound, fe.Param())
+	default:
+		return fmt.Sprintf("must be %s %s characters long", bound, fe.Param())
+	}
+}
+
+// Message возвращает понятное клиенту описание нарушенного правила
+func Message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "max":
+		return limitMessage(fe, "at most")
+	case "min":
+		return limitMessage(fe, "at least")
+	case "url":
+		return "must be a valid URL"
+	case "songdate":
+		return "must be a date in YYYY-MM-DD or DD.MM.YYYY format"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
+
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// songDate пропускает пустую строку: обязательность задаётся правилом required
+func songDate(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if value == "" {
+		return true
+	}
+
+	return ParseReleaseDate(value) != nil
+}
+
+// ParseReleaseDate разбирает дату выхода в одном из допустимых форматов, nil - формат не подошёл
+func ParseReleaseDate(value string) *time.Time {
+	for _, layout := range ReleaseDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"song-lib/internal/lib/errs"
+	"strings"
+	"testing"
+)
+
+type song struct {
+	Group       string  `json:"group" validate:"required,notblank,max=255"`
+	ReleaseDate string  `json:"release_date" validate:"omitempty,songdate"`
+	Link        *string `json:"link,omitempty" validate:"omitnil,url,max=255"`
+}
+
+func TestStruct(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		req    song
+		fields map[string]string
+	}{
+		{name: "valid", req: song{Group: "Muse", ReleaseDate: "16.07.2006", Link: ptr("https://example.com")}},
+		{name: "iso date without link", req: song{Group: "Muse", ReleaseDate: "2006-07-16"}},
+		{name: "missing group", req: song{}, fields: map[string]string{"group": "required"}},
+		{name: "blank group", req: song{Group: "   "}, fields: map[string]string{"group": "notblank"}},
+		{name: "long group", req: song{Group: strings.Repeat("я", 256)}, fields: map[string]string{"group": "max"}},
+		{
+			name:   "bad date and link",
+			req:    song{Group: "Muse", ReleaseDate: "2006/07/16", Link: ptr("not a url")},
+			fields: map[string]string{"release_date": "songdate", "link": "url"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(tt.req)
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, errs.ErrValidation) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			var validationErrs validator.ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("expected validator errors, got %v", err)
+			}
+
+			got := make(map[string]string, len(validationErrs))
+			for _, fe := range validationErrs {
+				got[fe.Field()] = fe.Tag()
+				if Message(fe) == "" {
+					t.Errorf("empty message for %s", fe.Field())
+				}
+			}
+			if len(got) != len(tt.fields) {
+				t.Fatalf("expected fields %v, got %v", tt.fields, got)
+			}
+			for field, tag := range tt.fields {
+				if got[field] != tag {
+					t.Errorf("field %s: expected rule %s, got %s", field, tag, got[field])
+				}
+			}
+		})
+	}
+}
+
+// TestMessageLimits - у чисел и списков ограничения min и max не про длину
+func TestMessageLimits(t *testing.T) {
+	type track struct {
+		Number int      `json:"number" validate:"min=1"`
+		Title  string   `json:"title" validate:"min=1"`
+		Tags   []string `json:"tags" validate:"max=1"`
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(Struct(track{Tags: []string{"rock", "live"}}), &validationErrs) {
+		t.Fatal("expected validator errors")
+	}
+
+	expected := map[string]string{
+		"number": "must be at least 1",
+		"title":  "must be at least 1 characters long",
+		"tags":   "must contain at most 1 items",
+	}
+	if len(validationErrs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), validationErrs)
+	}
+	for _, fe := range validationErrs {
+		if got := Message(fe); got != expected[fe.Field()] {
+			t.Errorf("field %s: expected %q, got %q", fe.Field(), expected[fe.Field()], got)
+		}
+	}
+}
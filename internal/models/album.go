@@ -0,0 +1,27 @@
+package models
+
+// Album - альбом исполнителя. Cover - ссылка на обложку
+type Album struct {
+	ID          int64  `json:"id"`
+	ArtistID    int64  `json:"artist_id"`
+	Artist      string `json:"artist"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	Cover       string `json:"cover"`
+	TrackCount  int64  `json:"track_count"`
+}
+
+// Track - место песни в альбоме. TrackNumber nil - номер трека неизвестен
+type Track struct {
+	DiscNumber  int
+	TrackNumber *int
+}
+
+// AlbumDetails - альбом песни из внешнего API
+type AlbumDetails struct {
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	Cover       string `json:"cover,omitempty"`
+	DiscNumber  int    `json:"disc_number,omitempty"`
+	TrackNumber int    `json:"track_number,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// Artist - исполнитель. Группа песни (Song.Group) - имя исполнителя
+type Artist struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	SongCount int64  `json:"song_count"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Playlist - плейлист пользователя. AllowDuplicates - одна песня может встречаться несколько раз
+type Playlist struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	AllowDuplicates bool      `json:"allow_duplicates"`
+	EntryCount      int64     `json:"entry_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// PlaylistEntry - запись плейлиста. Position - порядковый номер записи с 1
+type PlaylistEntry struct {
+	ID       int64     `json:"id"`
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+	Song     Song      `json:"song"`
+}
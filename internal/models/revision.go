@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// RevisionAuthorEnrichment - автор ревизий, созданных обогащением данными внешнего API
+const RevisionAuthorEnrichment = "enrichment"
+
+// MaxAuthorLength - максимальная длина автора ревизии
+const MaxAuthorLength = 255
+
+// SongRevision - полное состояние песни после изменения. Ревизии песни нумеруются с 1,
+// первая ревизия - песня при добавлении
+type SongRevision struct {
+	SongID    int64     `json:"song_id"`
+	Revision  int       `json:"revision"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Group       string `json:"group"`
+	Name        string `json:"name"`
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Операции строки построчного сравнения
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine - строка построчного сравнения: общая для обоих текстов, добавленная или удалённая
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// FieldChange - изменённое поле песни между двумя ревизиями
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RevisionDiff - изменения песни от ревизии From к ревизии To. Fields - изменённые поля, кроме текста,
+// Text - построчное сравнение текста, пустое, если текст не менялся
+type RevisionDiff struct {
+	SongID int64         `json:"song_id"`
+	From   int           `json:"from"`
+	To     int           `json:"to"`
+	Fields []FieldChange `json:"fields"`
+	Text   []DiffLine    `json:"text"`
+}
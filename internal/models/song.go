@@ -1,10 +1,160 @@
 package models
 
+import "time"
+
+// Поля песни в JSON представлении. Для release_date, text, link и album хранится источник (Provenance)
+const (
+	FieldGroup       = "group"
+	FieldName        = "name"
+	FieldReleaseDate = "release_date"
+	FieldText        = "text"
+	FieldLink        = "link"
+	FieldAlbum       = "album"
+)
+
+// ProvenanceManual - поле изменено пользователем, а не получено от внешнего API
+const ProvenanceManual = "manual"
+
+// Статусы обогащения песни данными внешнего API
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
 type Song struct {
-	ID          int64  `json:"id"`
-	Group       string `json:"group"`
-	Name        string `json:"name"`
-	ReleaseDate string `json:"release_date"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	ID               int64      `json:"id"`
+	ArtistID         int64      `json:"artist_id"`
+	Group            string     `json:"group"`
+	Name             string     `json:"name"`
+	ReleaseDate      string     `json:"release_date"`
+	Text             string     `json:"text"`
+	Link             string     `json:"link"`
+	EnrichmentStatus string     `json:"enrichment_status"`
+	EnrichedAt       *time.Time `json:"enriched_at,omitempty"`
+	// AlbumID, DiscNumber и TrackNumber - место песни в альбоме, nil - песня не в альбоме
+	AlbumID     *int64 `json:"album_id,omitempty"`
+	DiscNumber  *int   `json:"disc_number,omitempty"`
+	TrackNumber *int   `json:"track_number,omitempty"`
+	// Tags - теги песни по алфавиту
+	Tags []string `json:"tags"`
+	// Provenance - источник каждого поля: release_date, text, link, album
+	Provenance map[string]string `json:"provenance,omitempty"`
+	// DeletedAt - время переноса в корзину, nil - песня не в корзине
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SongPatch - частичное обновление песни (JSON Merge Patch, RFC 7396).
+// Ключ - поле песни в JSON представлении, nil - очистить поле
+type SongPatch map[string]*string
+
+// SongQuery - фильтры списка песен. Пустые значения не ограничивают выборку
+type SongQuery struct {
+	// ArtistID - только песни исполнителя
+	ArtistID int64
+	// Group и Name ищутся без учёта регистра как подстрока
+	Group string
+	Name  string
+	// ReleasedFrom и ReleasedTo - границы даты выхода включительно
+	ReleasedFrom *time.Time
+	ReleasedTo   *time.Time
+	HasText      *bool
+	HasLink      *bool
+	// Tags - песни с тегами, TagMode - все теги (TagModeAll) или любой из них (TagModeAny)
+	Tags    []string
+	TagMode string
+	// Search - разобранный параметр q (см. пакет internal/lib/search)
+	Search []SearchTerm
+	// Sort - порядок сортировки, при равенстве песни упорядочиваются по ID
+	Sort []SortField
+	// Cursor - продолжить с позиции курсора вместо смещения Page
+	Cursor *SongCursor
+	Page   int
+	Limit  int
+	// Count - посчитать общее число подходящих песен
+	Count bool
+	// Fuzzy - Group и Name находят также похожие значения (опечатки)
+	Fuzzy bool
+}
+
+// SongCursor - позиция в списке песен для постраничного вывода по ключу (keyset).
+// Values - значения полей сортировки граничной песни без ID, nil - значение NULL.
+// Backward - страница перед позицией, а не после неё
+type SongCursor struct {
+	Values   []*string
+	ID       int64
+	Backward bool
+}
+
+// SongList - страница списка песен. Next и Prev - курсоры соседних страниц, nil - страницы нет.
+// Total - число всех подходящих песен, nil - не считалось. DidYouMean - похожие группа и название,
+// если по точным фильтрам ничего не нашлось
+type SongList struct {
+	Items      []Song
+	Total      *int64
+	Next       *SongCursor
+	Prev       *SongCursor
+	DidYouMean *SongSuggestion
+}
+
+// SongSuggestion - исправление фильтров group и name с опечаткой. Пустое поле - исправления нет
+type SongSuggestion struct {
+	Group string `json:"group,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Поля, по которым можно сортировать список песен
+const (
+	SortID          = "id"
+	SortGroup       = FieldGroup
+	SortName        = FieldName
+	SortReleaseDate = FieldReleaseDate
+)
+
+// SortField - поле сортировки, Desc - по убыванию
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SearchTerm - условие поискового запроса. Пустое Field означает поиск по группе, названию и тексту
+type SearchTerm struct {
+	Field  string
+	Value  string
+	Negate bool
+}
+
+// SongSearchResult - песня, найденная полнотекстовым поиском. Snippet - куплет с совпадением
+// в виде HTML: текст экранирован, найденные слова обрамлены <mark></mark>. Verse - номер этого
+// куплета с 1 (0 - совпадение вне куплетов)
+type SongSearchResult struct {
+	Song
+	Rank    float64 `json:"rank"`
+	Verse   int     `json:"verse,omitempty"`
+	Snippet string  `json:"snippet"`
+}
+
+// Suggestion - вариант автодополнения и число песен с этим значением
+type Suggestion struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SongDetails - дополнительная информация о песне из внешнего API
+type SongDetails struct {
+	ReleaseDate string            `json:"release_date"`
+	Text        string            `json:"text"`
+	Link        string            `json:"link"`
+	Album       *AlbumDetails     `json:"album,omitempty"`
+	Provenance  map[string]string `json:"-"`
+	// Partial - часть источников не ответила, детали неполные и не кэшируются
+	Partial bool `json:"-"`
+}
+
+// CachedSongDetails - закэшированный ответ внешнего API. NotFound означает,
+// что API не знает песню (отрицательное кэширование)
+type CachedSongDetails struct {
+	Details   *SongDetails
+	NotFound  bool
+	ExpiresAt time.Time
 }
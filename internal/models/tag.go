@@ -0,0 +1,19 @@
+package models
+
+// Режимы фильтра по тегам: песня должна иметь все теги или хотя бы один
+const (
+	TagModeAll = "all"
+	TagModeAny = "any"
+)
+
+// MaxTagLength - максимальная длина тега в символах
+const MaxTagLength = 64
+
+// MaxSongTags - максимальное число тегов у песни
+const MaxSongTags = 20
+
+// TagCount - тег и число песен с ним
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
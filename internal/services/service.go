@@ -1,16 +1,82 @@
 package services
 
 import (
+	"fmt"
+	"slices"
 	"song-lib/internal/database/postgres"
+	"song-lib/internal/lib/diff"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSearchQueryLength - максимальная длина поискового запроса в символах
+const MaxSearchQueryLength = 255
+
+var (
+	// ErrInvalidStatus - неизвестный статус обогащения
+	ErrInvalidStatus error = errs.New(errs.ErrValidation, "status must be one of pending, failed, done")
+	// ErrInvalidSuggestField - автодополнение поддерживает только группу и название
+	ErrInvalidSuggestField error = errs.New(errs.ErrValidation, "field must be one of group, name")
+	// ErrInvalidPrefix - пустой или слишком длинный префикс автодополнения
+	ErrInvalidPrefix error = errs.New(errs.ErrValidation, fmt.Sprintf("prefix must be a non-empty string of at most %d characters", MaxSearchQueryLength))
+	// ErrInvalidSearchQuery - пустой или слишком длинный поисковый запрос
+	ErrInvalidSearchQuery error = errs.New(errs.ErrValidation, fmt.Sprintf("q must be a non-empty search query of at most %d characters", MaxSearchQueryLength))
+	// ErrInvalidTags - слишком много тегов или слишком длинный тег
+	ErrInvalidTags error = errs.New(errs.ErrValidation, fmt.Sprintf("at most %d tags of at most %d characters are allowed", models.MaxSongTags, models.MaxTagLength))
+	// ErrInvalidTagMode - неизвестный режим фильтра по тегам
+	ErrInvalidTagMode error = errs.New(errs.ErrValidation, "tag_mode must be one of all, any")
 )
 
 type ServiceSonger interface {
-	GetSongs(group, name string, page, limit int) ([]models.Song, error)
+	GetSongs(q models.SongQuery) (*models.SongList, error)
+	GetSong(id int64) (*models.Song, error)
 	AddSong(song *models.Song) (int64, error)
 	DeleteSong(id int64) (int64, error)
-	UpdateSong(song *models.Song) (int64, error)
+	UpdateSong(song *models.Song, author string) (int64, error)
+	PatchSong(id int64, patch models.SongPatch, author string) (*models.Song, error)
 	GetSongText(id int64) (*models.Song, error)
+	SearchSongs(q string, fuzzy bool, page, limit int) ([]models.SongSearchResult, bool, error)
+	SuggestSongs(field, prefix string, limit int) ([]models.Suggestion, error)
+	GetSongsByStatus(status string, page, limit int) ([]models.Song, error)
+	UpdateSongDetails(id int64, details *models.SongDetails) (int64, error)
+	SetEnrichmentStatus(id int64, status string) (int64, error)
+	SetEnrichmentFailed(id int64, permanent bool) (int64, error)
+	GetStaleSongs(before time.Time, limit int) ([]models.Song, error)
+	GetArtists(name string, page, limit int) ([]models.Artist, bool, error)
+	GetArtist(id int64) (*models.Artist, error)
+	AddArtist(artist *models.Artist) (int64, error)
+	UpdateArtist(artist *models.Artist) (int64, error)
+	DeleteArtist(id int64) (int64, error)
+	GetAlbums(artistID int64, title string, page, limit int) ([]models.Album, bool, error)
+	GetAlbum(id int64) (*models.Album, error)
+	AddAlbum(album *models.Album) (int64, error)
+	UpdateAlbum(album *models.Album) (int64, error)
+	DeleteAlbum(id int64) (int64, error)
+	GetAlbumTracks(id int64) ([]models.Song, error)
+	SetAlbumTrack(albumID, songID int64, track models.Track) (int64, error)
+	RemoveAlbumTrack(albumID, songID int64) (int64, error)
+	SetSongTags(id int64, tags []string) (*models.Song, error)
+	GetTagCloud(limit int) ([]models.TagCount, error)
+	GetPlaylists(page, limit int) ([]models.Playlist, bool, error)
+	GetPlaylist(id int64) (*models.Playlist, error)
+	AddPlaylist(playlist *models.Playlist) (int64, error)
+	UpdatePlaylist(playlist *models.Playlist) error
+	DeletePlaylist(id int64) (int64, error)
+	GetPlaylistEntries(id int64, page, limit int) ([]models.PlaylistEntry, bool, error)
+	AddPlaylistEntry(playlistID, songID int64, position int) (*models.PlaylistEntry, error)
+	MovePlaylistEntry(playlistID, entryID int64, position int) (*models.PlaylistEntry, error)
+	RemovePlaylistEntry(playlistID, entryID int64) error
+	ReorderPlaylist(playlistID int64, entryIDs []int64) error
+	GetTrash(page, limit int) ([]models.Song, bool, error)
+	RestoreSong(id int64) (*models.Song, error)
+	PurgeSongs(before time.Time, limit int) (int64, error)
+	GetSongRevisions(id int64, page, limit int) ([]models.SongRevision, bool, error)
+	GetSongRevision(id int64, revision int) (*models.SongRevision, error)
+	DiffSongRevisions(id int64, from, to int) (*models.RevisionDiff, error)
+	RestoreSongRevision(id int64, revision int, author string) (*models.Song, error)
 }
 
 type Service struct {
@@ -21,8 +87,28 @@ func New(db postgres.DBSonger) ServiceSonger {
 	return &Service{db: db}
 }
 
-func (s *Service) GetSongs(group, name string, page, limit int) ([]models.Song, error) {
-	return s.db.GetSongs(group, name, page, limit)
+func (s *Service) GetSongs(q models.SongQuery) (*models.SongList, error) {
+	const op = "internal.services.GetSongs"
+
+	switch q.TagMode {
+	case "":
+		q.TagMode = models.TagModeAll
+	case models.TagModeAll, models.TagModeAny:
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidTagMode)
+	}
+
+	tags, err := normalizeTags(q.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	q.Tags = tags
+
+	return s.db.GetSongs(q)
+}
+
+func (s *Service) GetSong(id int64) (*models.Song, error) {
+	return s.db.GetSong(id)
 }
 
 func (s *Service) AddSong(song *models.Song) (int64, error) {
@@ -30,13 +116,347 @@ func (s *Service) AddSong(song *models.Song) (int64, error) {
 }
 
 func (s *Service) DeleteSong(id int64) (int64, error) {
-	return s.db.DeleteSong(id)
+	const op = "internal.services.DeleteSong"
+
+	rowsAffected, err := s.db.DeleteSong(id)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrSongNotFound)
+	}
+	return rowsAffected, nil
 }
 
-func (s *Service) UpdateSong(song *models.Song) (int64, error) {
-	return s.db.UpdateSong(song)
+func (s *Service) UpdateSong(song *models.Song, author string) (int64, error) {
+	const op = "internal.services.UpdateSong"
+
+	rowsAffected, err := s.db.UpdateSong(song, author)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrSongNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) PatchSong(id int64, patch models.SongPatch, author string) (*models.Song, error) {
+	return s.db.PatchSong(id, patch, author)
 }
 
 func (s *Service) GetSongText(id int64) (*models.Song, error) {
 	return s.db.GetSongText(id)
 }
+
+func (s *Service) SearchSongs(q string, fuzzy bool, page, limit int) ([]models.SongSearchResult, bool, error) {
+	const op = "internal.services.SearchSongs"
+
+	q = strings.TrimSpace(q)
+	if q == "" || utf8.RuneCountInString(q) > MaxSearchQueryLength {
+		return nil, false, fmt.Errorf("%s: %w", op, ErrInvalidSearchQuery)
+	}
+	return s.db.SearchSongs(q, fuzzy, page, limit)
+}
+
+func (s *Service) SuggestSongs(field, prefix string, limit int) ([]models.Suggestion, error) {
+	const op = "internal.services.SuggestSongs"
+
+	switch field {
+	case models.FieldGroup, models.FieldName:
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidSuggestField)
+	}
+	if strings.TrimSpace(prefix) == "" || utf8.RuneCountInString(prefix) > MaxSearchQueryLength {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPrefix)
+	}
+	return s.db.SuggestSongs(field, prefix, limit)
+}
+
+func (s *Service) GetSongsByStatus(status string, page, limit int) ([]models.Song, error) {
+	const op = "internal.services.GetSongsByStatus"
+
+	switch status {
+	case models.EnrichmentPending, models.EnrichmentFailed, models.EnrichmentDone:
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidStatus)
+	}
+	return s.db.GetSongsByStatus(status, page, limit)
+}
+
+func (s *Service) UpdateSongDetails(id int64, details *models.SongDetails) (int64, error) {
+	return s.db.UpdateSongDetails(id, details)
+}
+
+func (s *Service) SetEnrichmentStatus(id int64, status string) (int64, error) {
+	return s.db.SetEnrichmentStatus(id, status)
+}
+
+func (s *Service) SetEnrichmentFailed(id int64, permanent bool) (int64, error) {
+	return s.db.SetEnrichmentFailed(id, permanent)
+}
+
+func (s *Service) GetStaleSongs(before time.Time, limit int) ([]models.Song, error) {
+	return s.db.GetStaleSongs(before, limit)
+}
+
+func (s *Service) GetArtists(name string, page, limit int) ([]models.Artist, bool, error) {
+	return s.db.GetArtists(name, page, limit)
+}
+
+func (s *Service) GetArtist(id int64) (*models.Artist, error) {
+	return s.db.GetArtist(id)
+}
+
+func (s *Service) AddArtist(artist *models.Artist) (int64, error) {
+	return s.db.AddArtist(artist)
+}
+
+func (s *Service) UpdateArtist(artist *models.Artist) (int64, error) {
+	const op = "internal.services.UpdateArtist"
+
+	rowsAffected, err := s.db.UpdateArtist(artist)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrArtistNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) DeleteArtist(id int64) (int64, error) {
+	const op = "internal.services.DeleteArtist"
+
+	rowsAffected, err := s.db.DeleteArtist(id)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrArtistNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) GetAlbums(artistID int64, title string, page, limit int) ([]models.Album, bool, error) {
+	return s.db.GetAlbums(artistID, title, page, limit)
+}
+
+func (s *Service) GetAlbum(id int64) (*models.Album, error) {
+	return s.db.GetAlbum(id)
+}
+
+func (s *Service) AddAlbum(album *models.Album) (int64, error) {
+	return s.db.AddAlbum(album)
+}
+
+func (s *Service) UpdateAlbum(album *models.Album) (int64, error) {
+	const op = "internal.services.UpdateAlbum"
+
+	rowsAffected, err := s.db.UpdateAlbum(album)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrAlbumNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) DeleteAlbum(id int64) (int64, error) {
+	const op = "internal.services.DeleteAlbum"
+
+	rowsAffected, err := s.db.DeleteAlbum(id)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrAlbumNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) GetAlbumTracks(id int64) ([]models.Song, error) {
+	return s.db.GetAlbumTracks(id)
+}
+
+func (s *Service) SetAlbumTrack(albumID, songID int64, track models.Track) (int64, error) {
+	const op = "internal.services.SetAlbumTrack"
+
+	rowsAffected, err := s.db.SetAlbumTrack(albumID, songID, track)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrSongNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) RemoveAlbumTrack(albumID, songID int64) (int64, error) {
+	const op = "internal.services.RemoveAlbumTrack"
+
+	rowsAffected, err := s.db.RemoveAlbumTrack(albumID, songID)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrTrackNotFound)
+	}
+	return rowsAffected, nil
+}
+
+func (s *Service) SetSongTags(id int64, tags []string) (*models.Song, error) {
+	const op = "internal.services.SetSongTags"
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.db.SetSongTags(id, tags)
+}
+
+func (s *Service) GetTagCloud(limit int) ([]models.TagCount, error) {
+	return s.db.GetTagCloud(limit)
+}
+
+// normalizeTags приводит теги к нижнему регистру без пробелов по краям, убирает пустые и повторы
+// и сортирует. Пустой результат - не nil, чтобы у песни можно было удалить все теги
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > models.MaxTagLength {
+			return nil, ErrInvalidTags
+		}
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > models.MaxSongTags {
+		return nil, ErrInvalidTags
+	}
+	return normalized, nil
+}
+
+func (s *Service) GetPlaylists(page, limit int) ([]models.Playlist, bool, error) {
+	return s.db.GetPlaylists(page, limit)
+}
+
+func (s *Service) GetPlaylist(id int64) (*models.Playlist, error) {
+	return s.db.GetPlaylist(id)
+}
+
+func (s *Service) AddPlaylist(playlist *models.Playlist) (int64, error) {
+	return s.db.AddPlaylist(playlist)
+}
+
+func (s *Service) UpdatePlaylist(playlist *models.Playlist) error {
+	return s.db.UpdatePlaylist(playlist)
+}
+
+func (s *Service) DeletePlaylist(id int64) (int64, error) {
+	const op = "internal.services.DeletePlaylist"
+
+	rowsAffected, err := s.db.DeletePlaylist(id)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, postgres.ErrPlaylistNotFound)
+	}
+	return rowsAffected, nil
+}
+
+// GetPlaylistEntries проверяет, что плейлист существует: у пустого плейлиста записей тоже нет
+func (s *Service) GetPlaylistEntries(id int64, page, limit int) ([]models.PlaylistEntry, bool, error) {
+	if _, err := s.db.GetPlaylist(id); err != nil {
+		return nil, false, err
+	}
+	return s.db.GetPlaylistEntries(id, page, limit)
+}
+
+func (s *Service) AddPlaylistEntry(playlistID, songID int64, position int) (*models.PlaylistEntry, error) {
+	return s.db.AddPlaylistEntry(playlistID, songID, position)
+}
+
+func (s *Service) MovePlaylistEntry(playlistID, entryID int64, position int) (*models.PlaylistEntry, error) {
+	return s.db.MovePlaylistEntry(playlistID, entryID, position)
+}
+
+func (s *Service) RemovePlaylistEntry(playlistID, entryID int64) error {
+	return s.db.RemovePlaylistEntry(playlistID, entryID)
+}
+
+func (s *Service) ReorderPlaylist(playlistID int64, entryIDs []int64) error {
+	return s.db.ReorderPlaylist(playlistID, entryIDs)
+}
+
+func (s *Service) GetTrash(page, limit int) ([]models.Song, bool, error) {
+	return s.db.GetTrash(page, limit)
+}
+
+func (s *Service) RestoreSong(id int64) (*models.Song, error) {
+	return s.db.RestoreSong(id)
+}
+
+func (s *Service) PurgeSongs(before time.Time, limit int) (int64, error) {
+	return s.db.PurgeSongs(before, limit)
+}
+
+// GetSongRevisions проверяет, что песня существует и не в корзине
+func (s *Service) GetSongRevisions(id int64, page, limit int) ([]models.SongRevision, bool, error) {
+	if _, err := s.db.GetSong(id); err != nil {
+		return nil, false, err
+	}
+	return s.db.GetSongRevisions(id, page, limit)
+}
+
+// GetSongRevision проверяет, что песня существует и не в корзине
+func (s *Service) GetSongRevision(id int64, revision int) (*models.SongRevision, error) {
+	if _, err := s.db.GetSong(id); err != nil {
+		return nil, err
+	}
+	return s.db.GetSongRevision(id, revision)
+}
+
+// DiffSongRevisions сравнивает ревизии from и to: изменённые поля и построчное сравнение текста
+func (s *Service) DiffSongRevisions(id int64, from, to int) (*models.RevisionDiff, error) {
+	const op = "internal.services.DiffSongRevisions"
+
+	before, err := s.GetSongRevision(id, from)
+	if err != nil {
+		return nil, fmt.Errorf("%s: from: %w", op, err)
+	}
+	after, err := s.db.GetSongRevision(id, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: to: %w", op, err)
+	}
+
+	result := &models.RevisionDiff{SongID: id, From: from, To: to, Fields: []models.FieldChange{}, Text: []models.DiffLine{}}
+	for _, field := range []struct {
+		name     string
+		from, to string
+	}{
+		{name: models.FieldGroup, from: before.Group, to: after.Group},
+		{name: models.FieldName, from: before.Name, to: after.Name},
+		{name: models.FieldReleaseDate, from: before.ReleaseDate, to: after.ReleaseDate},
+		{name: models.FieldLink, from: before.Link, to: after.Link},
+	} {
+		if field.from != field.to {
+			result.Fields = append(result.Fields, models.FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	if before.Text != after.Text {
+		result.Text = diff.Lines(before.Text, after.Text)
+	}
+	return result, nil
+}
+
+func (s *Service) RestoreSongRevision(id int64, revision int, author string) (*models.Song, error) {
+	return s.db.RestoreSongRevision(id, revision, author)
+}
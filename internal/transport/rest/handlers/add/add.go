@@ -1,53 +1,54 @@
 package add
 
 import (
-	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
 	"song-lib/internal/models"
-	"strings"
 )
 
 type Request struct {
-	Group string `json:"group" validate:"required"`
-	Song  string `json:"song" validate:"required"`
+	Group string `json:"group" validate:"required,notblank,max=255"`
+	Song  string `json:"song" validate:"required,notblank,max=255"`
 }
 
 type Response struct {
 	resp.Response
-	Msg string `json:"msg,omitempty"`
-}
-
-type SongDetails struct {
-	ReleaseDate string `json:"release_date"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	Msg              string `json:"msg,omitempty"`
+	ID               int64  `json:"id,omitempty"`
+	EnrichmentStatus string `json:"enrichment_status,omitempty"`
 }
 
 type SongAdder interface {
 	AddSong(song *models.Song) (int64, error)
 }
 
+type EnrichmentQueue interface {
+	Enqueue(id int64, group, song string) bool
+}
+
 // New adds a new song to the library
 // @Summary Add a new song
-// @Description Add a new song to the library and fetch additional details from an external API
+// @Description Add a new song to the library. Additional details are fetched from an external API in the background,
+// @Description progress is reported by the enrichment_status field
 // @Tags Songs
 // @Accept  json
 // @Produce  json
 // @Param song body add.Request true "Song details"
-// @Success 200 {object} add.Response "Song added successfully"
-// @Failure 400 {object} resp.Response "Invalid request"
-// @Failure 500 {object} resp.Response "Failed to add song"
+// @Success 202 {object} add.Response "Song accepted for enrichment"
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 409 {object} resp.Problem "Song already exists"
+// @Failure 500 {object} resp.Problem "Failed to add song"
 // @Router /songs [post]
-func New(log *slog.Logger, adder SongAdder) http.HandlerFunc {
+func New(log *slog.Logger, adder SongAdder, queue EnrichmentQueue) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "internal.transport.handlers.add.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -56,64 +57,40 @@ func New(log *slog.Logger, adder SongAdder) http.HandlerFunc {
 		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			log.Error("failed to decode request body", "error", err)
-			render.JSON(w, r, resp.Error("failed to decode request"))
+			resp.Fail(w, r, errs.ErrValidation, "failed to decode request")
 			return
 		}
 		log.Info("request body decoded", slog.Any("request", req))
 
-		if err := validator.New().Struct(req); err != nil {
+		if err := validation.Struct(req); err != nil {
 			log.Error("invalid request", "error", err)
-			render.JSON(w, r, resp.Error("invalid request"))
-			return
-		}
-
-		group := strings.Replace(req.Group, " ", "+", -1)
-		song := strings.Replace(req.Song, " ", "+", -1)
-		externalApiUrl := fmt.Sprintf("http://localhost:8081/info?group=%s&song=%s", group, song)
-		fmt.Println(externalApiUrl)
-
-		response, err := http.Get(externalApiUrl)
-		if err != nil {
-			log.Error("failed to send request", "error", err)
-			render.JSON(w, r, resp.Error("failed to send request"))
-			return
-		}
-		defer response.Body.Close()
-
-		if response.StatusCode != http.StatusOK {
-			log.Error("failed to send request", "status_code", response.StatusCode)
-			render.JSON(w, r, resp.Error("failed to send request"))
-			return
-		}
-
-		var songDetails SongDetails
-		err = render.DecodeJSON(response.Body, &songDetails)
-		if err != nil {
-			log.Error("failed to decode response", "error", err)
-			render.JSON(w, r, resp.Error("failed to decode response"))
+			resp.Fail(w, r, err, "invalid request")
 			return
 		}
 
 		newSong := &models.Song{
-			Group:       req.Group,
-			Name:        req.Song,
-			ReleaseDate: songDetails.ReleaseDate,
-			Text:        songDetails.Text,
-			Link:        songDetails.Link,
+			Group:            req.Group,
+			Name:             req.Song,
+			EnrichmentStatus: models.EnrichmentPending,
 		}
 
 		id, err := adder.AddSong(newSong)
 		if err != nil {
 			log.Error("failed to add song", "error", err)
-			render.JSON(w, r, resp.Error("failed to add song"))
+			resp.Fail(w, r, err, "failed to add song")
 			return
 		}
 
+		queue.Enqueue(id, newSong.Group, newSong.Name)
+
 		log.Info("song added successfully", slog.Int64("song_id", id))
 
+		render.Status(r, http.StatusAccepted)
 		render.JSON(w, r, Response{
-			Response: resp.OK(),
-			Msg:      "success",
+			Response:         resp.OK(),
+			Msg:              "success",
+			ID:               id,
+			EnrichmentStatus: newSong.EnrichmentStatus,
 		})
 	}
 }
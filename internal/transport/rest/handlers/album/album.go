@@ -0,0 +1,499 @@
+package album
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+type Request struct {
+	ArtistID    int64  `json:"artist_id" validate:"required,min=1"`
+	Title       string `json:"title" validate:"required,notblank,max=255"`
+	ReleaseDate string `json:"release_date" validate:"omitempty,songdate"`
+	Cover       string `json:"cover" validate:"omitempty,url,max=255"`
+}
+
+// TrackRequest - место песни в альбоме. Без disc_number песня попадает на первый диск,
+// без track_number - в конец диска
+type TrackRequest struct {
+	DiscNumber  int  `json:"disc_number" validate:"omitempty,min=1"`
+	TrackNumber *int `json:"track_number" validate:"omitnil,min=1"`
+}
+
+type Response struct {
+	resp.Response
+	Msg string `json:"msg,omitempty"`
+	ID  int64  `json:"id,omitempty"`
+}
+
+type ListResponse struct {
+	Items []models.Album `json:"items"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+	Next  string         `json:"next,omitempty"`
+}
+
+type TracksResponse struct {
+	Album models.Album  `json:"album"`
+	Items []models.Song `json:"items"`
+}
+
+type AlbumLister interface {
+	GetAlbums(artistID int64, title string, page, limit int) ([]models.Album, bool, error)
+}
+
+type AlbumGetter interface {
+	GetAlbum(id int64) (*models.Album, error)
+}
+
+type AlbumAdder interface {
+	AddAlbum(album *models.Album) (int64, error)
+}
+
+type AlbumUpdater interface {
+	UpdateAlbum(album *models.Album) (int64, error)
+}
+
+type AlbumDeleter interface {
+	DeleteAlbum(id int64) (int64, error)
+}
+
+type TrackGetter interface {
+	GetAlbum(id int64) (*models.Album, error)
+	GetAlbumTracks(id int64) ([]models.Song, error)
+}
+
+type TrackSetter interface {
+	SetAlbumTrack(albumID, songID int64, track models.Track) (int64, error)
+}
+
+type TrackRemover interface {
+	RemoveAlbumTrack(albumID, songID int64) (int64, error)
+}
+
+// NewList gets albums with pagination
+// @Summary Get albums
+// @Description Get albums in alphabetical order with the number of their tracks
+// @Tags Albums
+// @Param artist_id query int false "Only albums of the artist"
+// @Param title query string false "Part of the album title"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of albums per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} album.ListResponse
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 500 {object} resp.Problem "Failed to get albums"
+// @Router /albums [get]
+func NewList(log *slog.Logger, lister AlbumLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var artistID int64
+		if value := r.URL.Query().Get("artist_id"); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				log.Error("invalid artist id", "error", err)
+				resp.Fail(w, r, errs.New(errs.ErrValidation, "artist_id must be a positive integer"), "invalid query parameters")
+				return
+			}
+			artistID = id
+		}
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		albums, more, err := lister.GetAlbums(artistID, r.URL.Query().Get("title"), page, limit)
+		if err != nil {
+			log.Error("failed to get albums", "error", err)
+			resp.Fail(w, r, err, "failed to get albums")
+			return
+		}
+
+		response := ListResponse{Items: albums, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.Album{}
+		}
+
+		log.Info("albums retrieved successfully", slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
+
+// NewGet gets a single album by its ID
+// @Summary Get an album
+// @Description Get an album with its artist and the number of tracks
+// @Tags Albums
+// @Param id path int true "Album ID"
+// @Produce  json
+// @Success 200 {object} models.Album
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Album not found"
+// @Failure 500 {object} resp.Problem "Failed to get album"
+// @Router /albums/{id} [get]
+func NewGet(log *slog.Logger, getter AlbumGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewGet"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid album id", "error", err)
+			resp.Fail(w, r, err, "invalid album id")
+			return
+		}
+
+		album, err := getter.GetAlbum(id)
+		if err != nil {
+			log.Error("failed to get album", "error", err)
+			resp.Fail(w, r, err, "failed to get album")
+			return
+		}
+
+		log.Info("album retrieved successfully", slog.Int64("album_id", id))
+
+		render.JSON(w, r, album)
+	}
+}
+
+// NewCreate adds a new album
+// @Summary Add an album
+// @Description Add an album of an artist. Albums are also created automatically from external API details
+// @Tags Albums
+// @Accept  json
+// @Produce  json
+// @Param album body album.Request true "Album"
+// @Success 201 {object} album.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 409 {object} resp.Problem "Album already exists or artist does not exist"
+// @Failure 500 {object} resp.Problem "Failed to add album"
+// @Router /albums [post]
+func NewCreate(log *slog.Logger, adder AlbumAdder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		id, err := adder.AddAlbum(req.album(0))
+		if err != nil {
+			log.Error("failed to add album", "error", err)
+			resp.Fail(w, r, err, "failed to add album")
+			return
+		}
+
+		log.Info("album added successfully", slog.Int64("album_id", id))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+			ID:       id,
+		})
+	}
+}
+
+// NewUpdate changes the album
+// @Summary Update an album
+// @Description Update an album by its ID. The artist of an album with tracks cannot be changed
+// @Tags Albums
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Album ID"
+// @Param album body album.Request true "Album"
+// @Success 200 {object} album.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Album not found"
+// @Failure 409 {object} resp.Problem "The artist already has an album with this title, artist does not exist or album has tracks"
+// @Failure 500 {object} resp.Problem "Failed to update album"
+// @Router /albums/{id} [put]
+func NewUpdate(log *slog.Logger, updater AlbumUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewUpdate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid album id", "error", err)
+			resp.Fail(w, r, err, "invalid album id")
+			return
+		}
+
+		var req Request
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		if _, err := updater.UpdateAlbum(req.album(id)); err != nil {
+			log.Error("failed to update album", "error", err)
+			resp.Fail(w, r, err, "failed to update album")
+			return
+		}
+
+		log.Info("album updated successfully", slog.Int64("album_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewDelete deletes an album
+// @Summary Delete an album
+// @Description Delete an album. Its songs stay in the library without album and track numbers
+// @Tags Albums
+// @Param id path int true "Album ID"
+// @Produce  json
+// @Success 200 {object} album.Response
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Album not found"
+// @Failure 500 {object} resp.Problem "Failed to delete album"
+// @Router /albums/{id} [delete]
+func NewDelete(log *slog.Logger, deleter AlbumDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid album id", "error", err)
+			resp.Fail(w, r, err, "invalid album id")
+			return
+		}
+
+		if _, err := deleter.DeleteAlbum(id); err != nil {
+			log.Error("failed to delete album", "error", err)
+			resp.Fail(w, r, err, "failed to delete album")
+			return
+		}
+
+		log.Info("album deleted successfully", slog.Int64("album_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewTracks gets the album with its songs in track order
+// @Summary Get album tracks
+// @Description Get the album and its songs ordered by disc and track number, songs without a track number go last on their disc
+// @Tags Albums
+// @Param id path int true "Album ID"
+// @Produce  json
+// @Success 200 {object} album.TracksResponse
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Album not found"
+// @Failure 500 {object} resp.Problem "Failed to get tracks"
+// @Router /albums/{id}/tracks [get]
+func NewTracks(log *slog.Logger, getter TrackGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewTracks"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid album id", "error", err)
+			resp.Fail(w, r, err, "invalid album id")
+			return
+		}
+
+		album, err := getter.GetAlbum(id)
+		if err != nil {
+			log.Error("failed to get album", "error", err)
+			resp.Fail(w, r, err, "failed to get album")
+			return
+		}
+
+		tracks, err := getter.GetAlbumTracks(id)
+		if err != nil {
+			log.Error("failed to get tracks", "error", err)
+			resp.Fail(w, r, err, "failed to get tracks")
+			return
+		}
+		if tracks == nil {
+			tracks = []models.Song{}
+		}
+
+		log.Info("tracks retrieved successfully", slog.Int64("album_id", id), slog.Int("count", len(tracks)))
+
+		render.JSON(w, r, TracksResponse{
+			Album: *album,
+			Items: tracks,
+		})
+	}
+}
+
+// NewSetTrack places the song on the album
+// @Summary Add a song to an album
+// @Description Place a song on the album or move it to another disc or track number. A song belongs to at most one album
+// @Description and only to an album of its artist
+// @Tags Albums
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Album ID"
+// @Param song_id path int true "Song ID"
+// @Param track body album.TrackRequest true "Disc and track number"
+// @Success 200 {object} album.Response
+// @Failure 400 {object} resp.Problem "Invalid request or song of another artist, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Album or song not found"
+// @Failure 409 {object} resp.Problem "Track number is taken"
+// @Failure 500 {object} resp.Problem "Failed to set track"
+// @Router /albums/{id}/tracks/{song_id} [put]
+func NewSetTrack(log *slog.Logger, setter TrackSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewSetTrack"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		albumID, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid album id", "error", err)
+			resp.Fail(w, r, err, "invalid album id")
+			return
+		}
+		songID, err := resp.PathID(r, "song_id", "song_id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+
+		var req TrackRequest
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		track := models.Track{DiscNumber: req.DiscNumber, TrackNumber: req.TrackNumber}
+		if track.DiscNumber == 0 {
+			track.DiscNumber = 1
+		}
+
+		if _, err := setter.SetAlbumTrack(albumID, songID, track); err != nil {
+			log.Error("failed to set track", "error", err)
+			resp.Fail(w, r, err, "failed to set track")
+			return
+		}
+
+		log.Info("track set successfully", slog.Int64("album_id", albumID), slog.Int64("song_id", songID))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewRemoveTrack removes the song from the album
+// @Summary Remove a song from an album
+// @Description Remove a song from the album. The song stays in the library
+// @Tags Albums
+// @Param id path int true "Album ID"
+// @Param song_id path int true "Song ID"
+// @Produce  json
+// @Success 200 {object} album.Response
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song is not on the album"
+// @Failure 500 {object} resp.Problem "Failed to remove track"
+// @Router /albums/{id}/tracks/{song_id} [delete]
+func NewRemoveTrack(log *slog.Logger, remover TrackRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.album.NewRemoveTrack"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		albumID, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid album id", "error", err)
+			resp.Fail(w, r, err, "invalid album id")
+			return
+		}
+		songID, err := resp.PathID(r, "song_id", "song_id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+
+		if _, err := remover.RemoveAlbumTrack(albumID, songID); err != nil {
+			log.Error("failed to remove track", "error", err)
+			resp.Fail(w, r, err, "failed to remove track")
+			return
+		}
+
+		log.Info("track removed successfully", slog.Int64("album_id", albumID), slog.Int64("song_id", songID))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+func (req Request) album(id int64) *models.Album {
+	return &models.Album{
+		ID:          id,
+		ArtistID:    req.ArtistID,
+		Title:       req.Title,
+		ReleaseDate: req.ReleaseDate,
+		Cover:       req.Cover,
+	}
+}
+
+func decode(r *http.Request, req any) error {
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		return errs.Wrap(errs.ErrValidation, "failed to decode request", err)
+	}
+	return validation.Struct(req)
+}
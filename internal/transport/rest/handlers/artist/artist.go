@@ -0,0 +1,286 @@
+package artist
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required,notblank,max=255"`
+}
+
+type Response struct {
+	resp.Response
+	Msg string `json:"msg,omitempty"`
+	ID  int64  `json:"id,omitempty"`
+}
+
+type ListResponse struct {
+	Items []models.Artist `json:"items"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Next  string          `json:"next,omitempty"`
+}
+
+type ArtistLister interface {
+	GetArtists(name string, page, limit int) ([]models.Artist, bool, error)
+}
+
+type ArtistGetter interface {
+	GetArtist(id int64) (*models.Artist, error)
+}
+
+type ArtistAdder interface {
+	AddArtist(artist *models.Artist) (int64, error)
+}
+
+type ArtistUpdater interface {
+	UpdateArtist(artist *models.Artist) (int64, error)
+}
+
+type ArtistDeleter interface {
+	DeleteArtist(id int64) (int64, error)
+}
+
+// NewList gets artists with pagination
+// @Summary Get artists
+// @Description Get artists in alphabetical order with the number of their songs
+// @Tags Artists
+// @Param name query string false "Part of the artist name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of artists per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} artist.ListResponse
+// @Failure 500 {object} resp.Problem "Failed to get artists"
+// @Router /artists [get]
+func NewList(log *slog.Logger, lister ArtistLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.artist.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		artists, more, err := lister.GetArtists(r.URL.Query().Get("name"), page, limit)
+		if err != nil {
+			log.Error("failed to get artists", "error", err)
+			resp.Fail(w, r, err, "failed to get artists")
+			return
+		}
+
+		response := ListResponse{Items: artists, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.Artist{}
+		}
+
+		log.Info("artists retrieved successfully", slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
+
+// NewGet gets a single artist by its ID
+// @Summary Get an artist
+// @Description Get an artist with the number of their songs
+// @Tags Artists
+// @Param id path int true "Artist ID"
+// @Produce  json
+// @Success 200 {object} models.Artist
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Artist not found"
+// @Failure 500 {object} resp.Problem "Failed to get artist"
+// @Router /artists/{id} [get]
+func NewGet(log *slog.Logger, getter ArtistGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.artist.NewGet"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "artist id")
+		if err != nil {
+			log.Error("invalid artist id", "error", err)
+			resp.Fail(w, r, err, "invalid artist id")
+			return
+		}
+
+		artist, err := getter.GetArtist(id)
+		if err != nil {
+			log.Error("failed to get artist", "error", err)
+			resp.Fail(w, r, err, "failed to get artist")
+			return
+		}
+
+		log.Info("artist retrieved successfully", slog.Int64("artist_id", id))
+
+		render.JSON(w, r, artist)
+	}
+}
+
+// NewCreate adds a new artist
+// @Summary Add an artist
+// @Description Add an artist. Artists are also created automatically for new song groups
+// @Tags Artists
+// @Accept  json
+// @Produce  json
+// @Param artist body artist.Request true "Artist"
+// @Success 201 {object} artist.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 409 {object} resp.Problem "Artist already exists"
+// @Failure 500 {object} resp.Problem "Failed to add artist"
+// @Router /artists [post]
+func NewCreate(log *slog.Logger, adder ArtistAdder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.artist.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		req, err := decode(r)
+		if err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		id, err := adder.AddArtist(&models.Artist{Name: req.Name})
+		if err != nil {
+			log.Error("failed to add artist", "error", err)
+			resp.Fail(w, r, err, "failed to add artist")
+			return
+		}
+
+		log.Info("artist added successfully", slog.Int64("artist_id", id))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+			ID:       id,
+		})
+	}
+}
+
+// NewUpdate renames the artist
+// @Summary Rename an artist
+// @Description Rename an artist. The group of all their songs changes too
+// @Tags Artists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Artist ID"
+// @Param artist body artist.Request true "Artist"
+// @Success 200 {object} artist.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Artist not found"
+// @Failure 409 {object} resp.Problem "Another artist has this name"
+// @Failure 500 {object} resp.Problem "Failed to update artist"
+// @Router /artists/{id} [put]
+func NewUpdate(log *slog.Logger, updater ArtistUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.artist.NewUpdate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "artist id")
+		if err != nil {
+			log.Error("invalid artist id", "error", err)
+			resp.Fail(w, r, err, "invalid artist id")
+			return
+		}
+
+		req, err := decode(r)
+		if err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		if _, err := updater.UpdateArtist(&models.Artist{ID: id, Name: req.Name}); err != nil {
+			log.Error("failed to update artist", "error", err)
+			resp.Fail(w, r, err, "failed to update artist")
+			return
+		}
+
+		log.Info("artist updated successfully", slog.Int64("artist_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewDelete deletes an artist without songs
+// @Summary Delete an artist
+// @Description Delete an artist. Artists that still have songs cannot be deleted
+// @Tags Artists
+// @Param id path int true "Artist ID"
+// @Produce  json
+// @Success 200 {object} artist.Response
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Artist not found"
+// @Failure 409 {object} resp.Problem "Artist still has songs"
+// @Failure 500 {object} resp.Problem "Failed to delete artist"
+// @Router /artists/{id} [delete]
+func NewDelete(log *slog.Logger, deleter ArtistDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.artist.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "artist id")
+		if err != nil {
+			log.Error("invalid artist id", "error", err)
+			resp.Fail(w, r, err, "invalid artist id")
+			return
+		}
+
+		if _, err := deleter.DeleteArtist(id); err != nil {
+			log.Error("failed to delete artist", "error", err)
+			resp.Fail(w, r, err, "failed to delete artist")
+			return
+		}
+
+		log.Info("artist deleted successfully", slog.Int64("artist_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+func decode(r *http.Request) (Request, error) {
+	var req Request
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		return req, errs.Wrap(errs.ErrValidation, "failed to decode request", err)
+	}
+	if err := validation.Struct(req); err != nil {
+		return req, err
+	}
+	return req, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-chi/render"
 	"log/slog"
 	"net/http"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/lib/resp"
 	"strconv"
 )
@@ -19,21 +20,22 @@ type SongDeleter interface {
 	DeleteSong(id int64) (int64, error)
 }
 
-// New deletes a song from the library
+// New moves a song to the trash
 // @Summary Delete a song
-// @Description Delete a song from the library by its ID
+// @Description Move a song to the trash by its ID. The song can be restored until the trash is purged
 // @Tags Songs
 // @Param id path int true "Song ID"
 // @Produce  json
 // @Success 200 {object} del.Response
-// @Failure 404 {object} resp.Response "Song not found"
-// @Failure 500 {object} resp.Response "Failed to delete song"
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 500 {object} resp.Problem "Failed to delete song"
 // @Router /songs/{id} [delete]
 func New(log *slog.Logger, deleter SongDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "internal.transport.handlers.del.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -42,20 +44,13 @@ func New(log *slog.Logger, deleter SongDeleter) http.HandlerFunc {
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil || id <= 0 {
 			log.Error("invalid song id", "error", err)
-			render.JSON(w, r, resp.Error("invalid song id"))
+			resp.Fail(w, r, errs.ErrValidation, "invalid song id")
 			return
 		}
 
-		rowsAffected, err := deleter.DeleteSong(id)
-		if err != nil {
+		if _, err := deleter.DeleteSong(id); err != nil {
 			log.Error("failed to delete song", "error", err)
-			render.JSON(w, r, resp.Error("failed to delete song"))
-			return
-		}
-
-		if rowsAffected == 0 {
-			log.Error("song not found", slog.Int64("song_id", id))
-			render.JSON(w, r, resp.Error("song not found"))
+			resp.Fail(w, r, err, "failed to delete song")
 			return
 		}
 
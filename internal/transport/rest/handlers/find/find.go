@@ -0,0 +1,85 @@
+package find
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+type Response struct {
+	Items []models.SongSearchResult `json:"items"`
+	Page  int                       `json:"page"`
+	Limit int                       `json:"limit"`
+	Next  string                    `json:"next,omitempty"`
+}
+
+type SongSearcher interface {
+	SearchSongs(q string, fuzzy bool, page, limit int) ([]models.SongSearchResult, bool, error)
+}
+
+// New searches songs by lyrics, group and name
+// @Summary Full-text search
+// @Description Find songs by a remembered line of lyrics, group or name. Results are ranked by relevance,
+// @Description group and name matches weigh more than text matches. The query supports "quoted phrases",
+// @Description OR and a leading minus to exclude words. snippet is the best matching verse with found words
+// @Description wrapped in <mark></mark> and the rest HTML-escaped, verse is its number as in GET /songs/{id}/text.
+// @Description With fuzzy=true groups and names similar to the query are found too, e.g. Metalica
+// @Tags Songs
+// @Param q query string true "Search query, e.g. \"can you hear me\""
+// @Param fuzzy query bool false "Also find groups and names with typos" default(false)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of songs per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} find.Response
+// @Header 200 {string} Link "Link to the next page (RFC 8288)"
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 500 {object} resp.Problem "Failed to search songs"
+// @Router /songs/search [get]
+func New(log *slog.Logger, searcher SongSearcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.find.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q := r.URL.Query().Get("q")
+		page, limit := resp.Pagination(r.URL.Query())
+
+		fuzzy := false
+		if f := r.URL.Query().Get("fuzzy"); f != "" {
+			var err error
+			if fuzzy, err = strconv.ParseBool(f); err != nil {
+				log.Error("invalid fuzzy parameter", "error", err)
+				resp.Fail(w, r, errs.ErrValidation, "fuzzy must be true or false")
+				return
+			}
+		}
+
+		results, more, err := searcher.SearchSongs(q, fuzzy, page, limit)
+		if err != nil {
+			log.Error("failed to search songs", "error", err)
+			resp.Fail(w, r, err, "failed to search songs")
+			return
+		}
+
+		response := Response{Items: results, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.SongSearchResult{}
+		}
+
+		log.Info("songs found", slog.String("query", q), slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
@@ -1,84 +1,271 @@
 package get
 
 import (
+	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"song-lib/internal/lib/cursor"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/search"
+	"song-lib/internal/lib/validation"
 	"song-lib/internal/models"
 	"strconv"
+	"time"
 )
 
+// Response - страница списка. Page указывается только при переходе по номеру страницы,
+// Total - если не передан count=false. Next и Prev - ссылки на соседние страницы
+type Response struct {
+	Items      []models.Song `json:"items"`
+	Page       int           `json:"page,omitempty"`
+	Limit      int           `json:"limit"`
+	Total      *int64        `json:"total,omitempty"`
+	Next       string        `json:"next,omitempty"`
+	Prev       string        `json:"prev,omitempty"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	PrevCursor string        `json:"prev_cursor,omitempty"`
+	// DidYouMean - похожие группа и название, если по group и name ничего не нашлось
+	DidYouMean *models.SongSuggestion `json:"did_you_mean,omitempty"`
+}
+
 type SongGetter interface {
-	GetSongs(group, name string, page, limit int) ([]models.Song, error)
+	GetSongs(q models.SongQuery) (*models.SongList, error)
 }
 
-// New gets songs with pagination
-// @Summary Get song with pagination
-// @Description Get songs with pagination support
+// New gets songs with optional filters and pagination
+// @Summary Get songs with filters and pagination
+// @Description Get songs matching any combination of filters. group and name match a case-insensitive substring.
+// @Description q is a search query: words and "quoted phrases" match group, name or text, a field prefix
+// @Description (group:, name:, song:, text:, link:) limits a term to one field, a leading minus excludes matches.
+// @Description All conditions are combined with AND. Pages are linked by opaque next_cursor and prev_cursor
+// @Description that keep the chosen sort order stable while songs are added. The same pages are linked
+// @Description by next and prev URLs in the body and in the Link header. No matches is an empty list,
+// @Description with did_you_mean suggesting a similar group and name when they were searched without fuzzy
 // @Tags Songs
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Number of songs per page" default(10)
+// @Param group query string false "Part of the group name"
+// @Param name query string false "Part of the song name"
+// @Param q query string false "Search query, e.g. muse \"black hole\" -live"
+// @Param released_from query string false "Released on or after the date (YYYY-MM-DD or DD.MM.YYYY)"
+// @Param released_to query string false "Released on or before the date (YYYY-MM-DD or DD.MM.YYYY)"
+// @Param fuzzy query bool false "group and name also match values with typos" default(false)
+// @Param has_text query bool false "Only songs with (true) or without (false) text"
+// @Param has_link query bool false "Only songs with (true) or without (false) link"
+// @Param tag query []string false "Only songs with the tag, repeat for several tags" collectionFormat(multi)
+// @Param tag_mode query string false "Songs with all of the tags or with any of them" Enums(all, any) default(all)
+// @Param sort query string false "Comma-separated sort fields (id, group, name, release_date), a leading minus sorts descending, ties are broken by id" default(id)
+// @Param cursor query string false "Opaque cursor from next_cursor or prev_cursor of the previous response"
+// @Param page query int false "Page number, prefer cursor for deep pages" default(1)
+// @Param limit query int false "Number of songs per page, at most 100" default(10)
+// @Param count query bool false "Count total matching songs, false skips the COUNT query" default(true)
 // @Produce  json
-// @Success 200 {object} []models.Song
-// @Failure 400 {object} resp.Response "Invalid request"
-// @Failure 500 {object} resp.Response "Failed to get songs"
+// @Success 200 {object} get.Response
+// @Header 200 {string} Link "Links to the next and previous pages (RFC 8288)"
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 500 {object} resp.Problem "Failed to get songs"
 // @Router /songs [get]
 func New(log *slog.Logger, getter SongGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "internal.transport.handlers.get.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		group := r.URL.Query().Get("group")
-		name := r.URL.Query().Get("name")
-		if group == "" || name == "" {
-			log.Error("missing group or name in query parameters")
-			render.JSON(w, r, resp.Error("group and name parameters are required"))
+		q, err := parseQuery(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", "error", err)
+			resp.Fail(w, r, err, "invalid query parameters")
 			return
 		}
-		log.Info("request parameters decoded", slog.String("group", group), slog.String("name", name))
+		log.Info("request parameters decoded", slog.String("query", r.URL.RawQuery))
+
+		list(w, r, log, getter, q)
+	}
+}
+
+type ArtistGetter interface {
+	GetArtist(id int64) (*models.Artist, error)
+}
+
+// NewByArtist gets songs of the artist with the same filters and pagination as GET /songs
+// @Summary Get songs of an artist
+// @Description Get songs of an artist. Accepts the same filters, sorting and pagination as GET /songs
+// @Tags Artists
+// @Param id path int true "Artist ID"
+// @Param name query string false "Part of the song name"
+// @Param q query string false "Search query, e.g. \"black hole\" -live"
+// @Param tag query []string false "Only songs with the tag, repeat for several tags" collectionFormat(multi)
+// @Param tag_mode query string false "Songs with all of the tags or with any of them" Enums(all, any) default(all)
+// @Param sort query string false "Comma-separated sort fields (id, group, name, release_date), a leading minus sorts descending, ties are broken by id" default(id)
+// @Param cursor query string false "Opaque cursor from next_cursor or prev_cursor of the previous response"
+// @Param page query int false "Page number, prefer cursor for deep pages" default(1)
+// @Param limit query int false "Number of songs per page, at most 100" default(10)
+// @Param count query bool false "Count total matching songs, false skips the COUNT query" default(true)
+// @Produce  json
+// @Success 200 {object} get.Response
+// @Header 200 {string} Link "Links to the next and previous pages (RFC 8288)"
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Artist not found"
+// @Failure 500 {object} resp.Problem "Failed to get songs"
+// @Router /artists/{id}/songs [get]
+func NewByArtist(log *slog.Logger, artists ArtistGetter, getter SongGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.get.NewByArtist"
 
-		page, limit := parsePagination(r)
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
 
-		songs, err := getter.GetSongs(group, name, page, limit)
+		id, err := resp.PathID(r, "id", "artist id")
 		if err != nil {
-			log.Error("failed to get songs", "error", err)
-			render.JSON(w, r, resp.Error("failed to get songs"))
+			log.Error("invalid artist id", "error", err)
+			resp.Fail(w, r, err, "invalid artist id")
 			return
 		}
 
-		if len(songs) == 0 {
-			log.Info("no songs found for request", slog.String("group", group), slog.String("name", name))
-			render.JSON(w, r, resp.Error("no songs found"))
+		if _, err := artists.GetArtist(id); err != nil {
+			log.Error("failed to get artist", "error", err)
+			resp.Fail(w, r, err, "failed to get artist")
 			return
 		}
 
-		log.Info("songs retrieved successfully", slog.Int("count", len(songs)))
+		q, err := parseQuery(r.URL.Query())
+		if err != nil {
+			log.Error("invalid query parameters", "error", err)
+			resp.Fail(w, r, err, "invalid query parameters")
+			return
+		}
+		q.ArtistID = id
+		log.Info("request parameters decoded", slog.Int64("artist_id", id), slog.String("query", r.URL.RawQuery))
 
-		render.JSON(w, r, songs)
+		list(w, r, log, getter, q)
 	}
 }
 
-func parsePagination(r *http.Request) (int, int) {
-	page := 1
-	limit := 10
+// list отдаёт страницу песен со ссылками на соседние страницы
+func list(w http.ResponseWriter, r *http.Request, log *slog.Logger, getter SongGetter, q models.SongQuery) {
+	songs, err := getter.GetSongs(q)
+	if err != nil {
+		log.Error("failed to get songs", "error", err)
+		resp.Fail(w, r, err, "failed to get songs")
+		return
+	}
+	if songs.Items == nil {
+		songs.Items = []models.Song{}
+	}
 
-	if p := r.URL.Query().Get("page"); p != "" {
-		if pInt, err := strconv.Atoi(p); err == nil && pInt > 0 {
-			page = pInt
-		}
+	log.Info("songs retrieved successfully", slog.Int("count", len(songs.Items)))
+
+	response := Response{
+		Items:      songs.Items,
+		Limit:      q.Limit,
+		Total:      songs.Total,
+		NextCursor: cursor.Encode(q.Sort, songs.Next),
+		PrevCursor: cursor.Encode(q.Sort, songs.Prev),
+		DidYouMean: songs.DidYouMean,
+	}
+	if q.Cursor == nil {
+		response.Page = q.Page
+	}
+	if response.NextCursor != "" {
+		response.Next = resp.PageURL(r, map[string]string{"cursor": response.NextCursor, "page": ""})
+	}
+	if response.PrevCursor != "" {
+		response.Prev = resp.PageURL(r, map[string]string{"cursor": response.PrevCursor, "page": ""})
 	}
 
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
-			limit = lInt
-		}
+	resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next}, resp.Link{Rel: "prev", URL: response.Prev})
+	render.JSON(w, r, response)
+}
+
+// parseQuery собирает фильтры из параметров запроса, все фильтры необязательны
+func parseQuery(values url.Values) (models.SongQuery, error) {
+	q := models.SongQuery{
+		Group:   values.Get("group"),
+		Name:    values.Get("name"),
+		Tags:    values["tag"],
+		TagMode: values.Get("tag_mode"),
+		Count:   true,
 	}
+	q.Page, q.Limit = resp.Pagination(values)
 
-	return page, limit
+	var err error
+	if q.ReleasedFrom, err = parseDate(values, "released_from"); err != nil {
+		return q, err
+	}
+	if q.ReleasedTo, err = parseDate(values, "released_to"); err != nil {
+		return q, err
+	}
+	if q.ReleasedFrom != nil && q.ReleasedTo != nil && q.ReleasedFrom.After(*q.ReleasedTo) {
+		return q, errs.New(errs.ErrValidation, "released_from must not be after released_to")
+	}
+
+	if q.HasText, err = parseBool(values, "has_text"); err != nil {
+		return q, err
+	}
+	if q.HasLink, err = parseBool(values, "has_link"); err != nil {
+		return q, err
+	}
+	fuzzy, err := parseBool(values, "fuzzy")
+	if err != nil {
+		return q, err
+	}
+	if fuzzy != nil {
+		q.Fuzzy = *fuzzy
+	}
+	count, err := parseBool(values, "count")
+	if err != nil {
+		return q, err
+	}
+	if count != nil {
+		q.Count = *count
+	}
+
+	if q.Search, err = search.Parse(values.Get("q")); err != nil {
+		return q, err
+	}
+	if q.Sort, err = search.ParseSort(values.Get("sort")); err != nil {
+		return q, err
+	}
+
+	if q.Cursor, err = cursor.Decode(values.Get("cursor"), q.Sort); err != nil {
+		return q, err
+	}
+	if q.Cursor != nil && q.Page > 1 {
+		return q, errs.New(errs.ErrValidation, "page cannot be combined with cursor")
+	}
+
+	return q, nil
+}
+
+func parseDate(values url.Values, key string) (*time.Time, error) {
+	value := values.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	date := validation.ParseReleaseDate(value)
+	if date == nil {
+		return nil, errs.New(errs.ErrValidation, fmt.Sprintf("%s must be a date in YYYY-MM-DD or DD.MM.YYYY format", key))
+	}
+	return date, nil
+}
+
+func parseBool(values url.Values, key string) (*bool, error) {
+	value := values.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errs.New(errs.ErrValidation, fmt.Sprintf("%s must be true or false", key))
+	}
+	return &b, nil
 }
@@ -0,0 +1,157 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"mime"
+	"net/http"
+	"song-lib/internal/lib/author"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+// Request - документ JSON Merge Patch. Отсутствующие поля не меняются, null очищает поле
+type Request struct {
+	Group       *string `json:"group,omitempty" validate:"omitnil,notblank,max=255"`
+	Name        *string `json:"name,omitempty" validate:"omitnil,notblank,max=255"`
+	ReleaseDate *string `json:"release_date,omitempty" validate:"omitnil,songdate"`
+	Text        *string `json:"text,omitempty"`
+	Link        *string `json:"link,omitempty" validate:"omitnil,url,max=255"`
+}
+
+type SongPatcher interface {
+	PatchSong(id int64, patch models.SongPatch, author string) (*models.Song, error)
+}
+
+// New partially updates the song in the library
+// @Summary Partially update a song
+// @Description Update only the provided fields of a song using JSON Merge Patch (RFC 7396) semantics:
+// @Description absent fields are left unchanged, null clears release_date, text or link.
+// @Description The change is saved as a new revision of the song
+// @Tags Songs
+// @Accept  json
+// @Accept  application/merge-patch+json
+// @Param id path int true "Song ID"
+// @Param X-Author header string false "Author of the change, saved in the revision"
+// @Param song body patch.Request true "Fields to change"
+// @Produce  json
+// @Success 200 {object} models.Song
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 415 {object} resp.Problem "Unsupported content type"
+// @Failure 500 {object} resp.Problem "Failed to update song"
+// @Router /songs/{id} [patch]
+func New(log *slog.Logger, patcher SongPatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.patch.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, errs.ErrValidation, "invalid song id")
+			return
+		}
+
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			mediaType, _, err := mime.ParseMediaType(ct)
+			if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+				log.Error("unsupported content type", slog.String("content_type", ct))
+				resp.WriteProblem(w, resp.NewProblem(r, http.StatusUnsupportedMediaType, "content type must be application/merge-patch+json"))
+				return
+			}
+		}
+
+		var doc map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil || doc == nil {
+			log.Error("failed to decode request body", "error", err)
+			resp.Fail(w, r, errs.ErrValidation, "request body must be a JSON object")
+			return
+		}
+
+		songPatch, err := parse(doc)
+		if err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		changedBy, err := author.FromRequest(r)
+		if err != nil {
+			log.Error("invalid author", "error", err)
+			resp.Fail(w, r, err, "invalid author")
+			return
+		}
+
+		song, err := patcher.PatchSong(id, songPatch, changedBy)
+		if err != nil {
+			log.Error("failed to patch song", "error", err)
+			resp.Fail(w, r, err, "failed to update song")
+			return
+		}
+
+		log.Info("song patched successfully", slog.Int64("song_id", id), slog.Int("fields", len(songPatch)))
+
+		render.JSON(w, r, song)
+	}
+}
+
+// parse проверяет документ патча: известные поля, строковые значения, запрет null для группы
+// и названия, затем правила полей те же, что у PUT
+func parse(doc map[string]json.RawMessage) (models.SongPatch, error) {
+	songPatch := make(models.SongPatch, len(doc))
+	for field, raw := range doc {
+		switch field {
+		case models.FieldGroup, models.FieldName, models.FieldReleaseDate, models.FieldText, models.FieldLink:
+		default:
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("unknown field %q", field))
+		}
+
+		if string(raw) == "null" {
+			if field == models.FieldGroup || field == models.FieldName {
+				return nil, errs.New(errs.ErrValidation, fmt.Sprintf("field %q cannot be null", field))
+			}
+			songPatch[field] = nil
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, errs.New(errs.ErrValidation, fmt.Sprintf("field %q must be a string or null", field))
+		}
+		songPatch[field] = &value
+	}
+
+	// Пустые дата и ссылка допустимы так же, как в PUT, поэтому проверяются только непустые значения
+	req := Request{
+		Group:       songPatch[models.FieldGroup],
+		Name:        songPatch[models.FieldName],
+		ReleaseDate: nonEmpty(songPatch[models.FieldReleaseDate]),
+		Text:        songPatch[models.FieldText],
+		Link:        nonEmpty(songPatch[models.FieldLink]),
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	return songPatch, nil
+}
+
+func nonEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
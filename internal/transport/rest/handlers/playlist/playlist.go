@@ -0,0 +1,601 @@
+package playlist
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+// Request - плейлист. Без allow_duplicates повторы разрешены
+type Request struct {
+	Name            string `json:"name" validate:"required,notblank,max=255"`
+	Description     string `json:"description" validate:"max=2000"`
+	AllowDuplicates *bool  `json:"allow_duplicates"`
+}
+
+// EntryRequest - песня для плейлиста. Без position песня добавляется в конец
+type EntryRequest struct {
+	SongID   int64 `json:"song_id" validate:"required,min=1"`
+	Position int   `json:"position" validate:"omitempty,min=1"`
+}
+
+type MoveRequest struct {
+	Position int `json:"position" validate:"required,min=1"`
+}
+
+// OrderRequest - все записи плейлиста в новом порядке
+type OrderRequest struct {
+	EntryIDs []int64 `json:"entry_ids" validate:"required,dive,min=1"`
+}
+
+type Response struct {
+	resp.Response
+	Msg string `json:"msg,omitempty"`
+	ID  int64  `json:"id,omitempty"`
+}
+
+type ListResponse struct {
+	Items []models.Playlist `json:"items"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+	Next  string            `json:"next,omitempty"`
+}
+
+type EntriesResponse struct {
+	Items []models.PlaylistEntry `json:"items"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+	Next  string                 `json:"next,omitempty"`
+}
+
+type PlaylistLister interface {
+	GetPlaylists(page, limit int) ([]models.Playlist, bool, error)
+}
+
+type PlaylistGetter interface {
+	GetPlaylist(id int64) (*models.Playlist, error)
+}
+
+type PlaylistAdder interface {
+	AddPlaylist(playlist *models.Playlist) (int64, error)
+}
+
+type PlaylistUpdater interface {
+	UpdatePlaylist(playlist *models.Playlist) error
+}
+
+type PlaylistDeleter interface {
+	DeletePlaylist(id int64) (int64, error)
+}
+
+type EntryLister interface {
+	GetPlaylistEntries(id int64, page, limit int) ([]models.PlaylistEntry, bool, error)
+}
+
+type EntryAdder interface {
+	AddPlaylistEntry(playlistID, songID int64, position int) (*models.PlaylistEntry, error)
+}
+
+type EntryMover interface {
+	MovePlaylistEntry(playlistID, entryID int64, position int) (*models.PlaylistEntry, error)
+}
+
+type EntryRemover interface {
+	RemovePlaylistEntry(playlistID, entryID int64) error
+}
+
+type EntryReorderer interface {
+	ReorderPlaylist(playlistID int64, entryIDs []int64) error
+}
+
+// NewList gets playlists with pagination
+// @Summary Get playlists
+// @Description Get playlists with the number of their entries, newest first
+// @Tags Playlists
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of playlists per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} playlist.ListResponse
+// @Failure 500 {object} resp.Problem "Failed to get playlists"
+// @Router /playlists [get]
+func NewList(log *slog.Logger, lister PlaylistLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		playlists, more, err := lister.GetPlaylists(page, limit)
+		if err != nil {
+			log.Error("failed to get playlists", "error", err)
+			resp.Fail(w, r, err, "failed to get playlists")
+			return
+		}
+
+		response := ListResponse{Items: playlists, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.Playlist{}
+		}
+
+		log.Info("playlists retrieved successfully", slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
+
+// NewGet gets a single playlist by its ID
+// @Summary Get a playlist
+// @Description Get a playlist with the number of its entries
+// @Tags Playlists
+// @Param id path int true "Playlist ID"
+// @Produce  json
+// @Success 200 {object} models.Playlist
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Playlist not found"
+// @Failure 500 {object} resp.Problem "Failed to get playlist"
+// @Router /playlists/{id} [get]
+func NewGet(log *slog.Logger, getter PlaylistGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewGet"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+
+		playlist, err := getter.GetPlaylist(id)
+		if err != nil {
+			log.Error("failed to get playlist", "error", err)
+			resp.Fail(w, r, err, "failed to get playlist")
+			return
+		}
+
+		log.Info("playlist retrieved successfully", slog.Int64("playlist_id", id))
+
+		render.JSON(w, r, playlist)
+	}
+}
+
+// NewCreate adds a new playlist
+// @Summary Add a playlist
+// @Description Add an empty playlist. Duplicate songs are allowed unless allow_duplicates is false
+// @Tags Playlists
+// @Accept  json
+// @Produce  json
+// @Param playlist body playlist.Request true "Playlist"
+// @Success 201 {object} playlist.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 500 {object} resp.Problem "Failed to add playlist"
+// @Router /playlists [post]
+func NewCreate(log *slog.Logger, adder PlaylistAdder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		id, err := adder.AddPlaylist(req.playlist(0))
+		if err != nil {
+			log.Error("failed to add playlist", "error", err)
+			resp.Fail(w, r, err, "failed to add playlist")
+			return
+		}
+
+		log.Info("playlist added successfully", slog.Int64("playlist_id", id))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+			ID:       id,
+		})
+	}
+}
+
+// NewUpdate changes the playlist
+// @Summary Update a playlist
+// @Description Update the name, description and duplicate policy of a playlist.
+// @Description Duplicates cannot be disallowed while the playlist has them
+// @Tags Playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param playlist body playlist.Request true "Playlist"
+// @Success 200 {object} playlist.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Playlist not found"
+// @Failure 409 {object} resp.Problem "Playlist has duplicate songs"
+// @Failure 500 {object} resp.Problem "Failed to update playlist"
+// @Router /playlists/{id} [put]
+func NewUpdate(log *slog.Logger, updater PlaylistUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewUpdate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+
+		var req Request
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		if err := updater.UpdatePlaylist(req.playlist(id)); err != nil {
+			log.Error("failed to update playlist", "error", err)
+			resp.Fail(w, r, err, "failed to update playlist")
+			return
+		}
+
+		log.Info("playlist updated successfully", slog.Int64("playlist_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewDelete deletes a playlist
+// @Summary Delete a playlist
+// @Description Delete a playlist with its entries. The songs stay in the library
+// @Tags Playlists
+// @Param id path int true "Playlist ID"
+// @Produce  json
+// @Success 200 {object} playlist.Response
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Playlist not found"
+// @Failure 500 {object} resp.Problem "Failed to delete playlist"
+// @Router /playlists/{id} [delete]
+func NewDelete(log *slog.Logger, deleter PlaylistDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+
+		if _, err := deleter.DeletePlaylist(id); err != nil {
+			log.Error("failed to delete playlist", "error", err)
+			resp.Fail(w, r, err, "failed to delete playlist")
+			return
+		}
+
+		log.Info("playlist deleted successfully", slog.Int64("playlist_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewEntries gets playlist entries in order
+// @Summary Get playlist entries
+// @Description Get entries of a playlist in order with their songs. Positions start from 1 and have no gaps
+// @Tags Playlists
+// @Param id path int true "Playlist ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of entries per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} playlist.EntriesResponse
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Playlist not found"
+// @Failure 500 {object} resp.Problem "Failed to get entries"
+// @Router /playlists/{id}/entries [get]
+func NewEntries(log *slog.Logger, lister EntryLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewEntries"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		entries, more, err := lister.GetPlaylistEntries(id, page, limit)
+		if err != nil {
+			log.Error("failed to get entries", "error", err)
+			resp.Fail(w, r, err, "failed to get entries")
+			return
+		}
+
+		response := EntriesResponse{Items: entries, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.PlaylistEntry{}
+		}
+
+		log.Info("entries retrieved successfully", slog.Int64("playlist_id", id), slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
+
+// NewAddEntry adds a song to the playlist
+// @Summary Add a song to a playlist
+// @Description Insert a song at the position, the following entries move down. Without position the song is appended
+// @Tags Playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param entry body playlist.EntryRequest true "Song and position"
+// @Success 201 {object} models.PlaylistEntry
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Playlist or song not found"
+// @Failure 409 {object} resp.Problem "Song is already in a playlist without duplicates"
+// @Failure 500 {object} resp.Problem "Failed to add entry"
+// @Router /playlists/{id}/entries [post]
+func NewAddEntry(log *slog.Logger, adder EntryAdder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewAddEntry"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+
+		var req EntryRequest
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		entry, err := adder.AddPlaylistEntry(id, req.SongID, req.Position)
+		if err != nil {
+			log.Error("failed to add entry", "error", err)
+			resp.Fail(w, r, err, "failed to add entry")
+			return
+		}
+
+		log.Info("entry added successfully", slog.Int64("playlist_id", id), slog.Int64("entry_id", entry.ID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, entry)
+	}
+}
+
+// NewMoveEntry moves the entry to another position
+// @Summary Move a playlist entry
+// @Description Move an entry to the position, the entries in between shift by one. A position past the end moves the entry last
+// @Tags Playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param entry_id path int true "Entry ID"
+// @Param position body playlist.MoveRequest true "New position"
+// @Success 200 {object} models.PlaylistEntry
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Playlist or entry not found"
+// @Failure 500 {object} resp.Problem "Failed to move entry"
+// @Router /playlists/{id}/entries/{entry_id} [patch]
+func NewMoveEntry(log *slog.Logger, mover EntryMover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewMoveEntry"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+		entryID, err := resp.PathID(r, "entry_id", "entry_id")
+		if err != nil {
+			log.Error("invalid entry id", "error", err)
+			resp.Fail(w, r, err, "invalid entry id")
+			return
+		}
+
+		var req MoveRequest
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		entry, err := mover.MovePlaylistEntry(id, entryID, req.Position)
+		if err != nil {
+			log.Error("failed to move entry", "error", err)
+			resp.Fail(w, r, err, "failed to move entry")
+			return
+		}
+
+		log.Info("entry moved successfully", slog.Int64("playlist_id", id), slog.Int64("entry_id", entryID), slog.Int("position", entry.Position))
+
+		render.JSON(w, r, entry)
+	}
+}
+
+// NewRemoveEntry removes the entry from the playlist
+// @Summary Remove a playlist entry
+// @Description Remove an entry, the following entries move up. The song stays in the library
+// @Tags Playlists
+// @Param id path int true "Playlist ID"
+// @Param entry_id path int true "Entry ID"
+// @Produce  json
+// @Success 200 {object} playlist.Response
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Playlist or entry not found"
+// @Failure 500 {object} resp.Problem "Failed to remove entry"
+// @Router /playlists/{id}/entries/{entry_id} [delete]
+func NewRemoveEntry(log *slog.Logger, remover EntryRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewRemoveEntry"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+		entryID, err := resp.PathID(r, "entry_id", "entry_id")
+		if err != nil {
+			log.Error("invalid entry id", "error", err)
+			resp.Fail(w, r, err, "invalid entry id")
+			return
+		}
+
+		if err := remover.RemovePlaylistEntry(id, entryID); err != nil {
+			log.Error("failed to remove entry", "error", err)
+			resp.Fail(w, r, err, "failed to remove entry")
+			return
+		}
+
+		log.Info("entry removed successfully", slog.Int64("playlist_id", id), slog.Int64("entry_id", entryID))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+// NewReorder sets a new order of all playlist entries
+// @Summary Reorder a playlist
+// @Description Set a new order of the playlist. entry_ids must list every entry exactly once,
+// @Description an outdated list (an entry was added or removed meanwhile) is rejected with 409
+// @Tags Playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param order body playlist.OrderRequest true "Entry IDs in the new order"
+// @Success 200 {object} playlist.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Playlist not found"
+// @Failure 409 {object} resp.Problem "Entry IDs do not match the playlist"
+// @Failure 500 {object} resp.Problem "Failed to reorder playlist"
+// @Router /playlists/{id}/entries [put]
+func NewReorder(log *slog.Logger, reorderer EntryReorderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.playlist.NewReorder"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "id")
+		if err != nil {
+			log.Error("invalid playlist id", "error", err)
+			resp.Fail(w, r, err, "invalid playlist id")
+			return
+		}
+
+		var req OrderRequest
+		if err := decode(r, &req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		if err := reorderer.ReorderPlaylist(id, req.EntryIDs); err != nil {
+			log.Error("failed to reorder playlist", "error", err)
+			resp.Fail(w, r, err, "failed to reorder playlist")
+			return
+		}
+
+		log.Info("playlist reordered successfully", slog.Int64("playlist_id", id), slog.Int("count", len(req.EntryIDs)))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Msg:      "success",
+		})
+	}
+}
+
+func (req Request) playlist(id int64) *models.Playlist {
+	allowDuplicates := true
+	if req.AllowDuplicates != nil {
+		allowDuplicates = *req.AllowDuplicates
+	}
+
+	return &models.Playlist{
+		ID:              id,
+		Name:            req.Name,
+		Description:     req.Description,
+		AllowDuplicates: allowDuplicates,
+	}
+}
+
+func decode(r *http.Request, req any) error {
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		return errs.Wrap(errs.ErrValidation, "failed to decode request", err)
+	}
+	return validation.Struct(req)
+}
@@ -0,0 +1,58 @@
+package progress
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+)
+
+type SongStatusGetter interface {
+	GetSongsByStatus(status string, page, limit int) ([]models.Song, error)
+}
+
+// New gets songs by enrichment status
+// @Summary Get songs by enrichment status
+// @Description Get songs that are still waiting for external API details or failed to get them
+// @Tags Enrichment
+// @Param status query string false "Enrichment status" Enums(pending, failed, done) default(pending)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of songs per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} []models.Song
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 500 {object} resp.Problem "Failed to get songs"
+// @Router /songs/enrichment [get]
+func New(log *slog.Logger, getter SongStatusGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.progress.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = models.EnrichmentPending
+		}
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		songs, err := getter.GetSongsByStatus(status, page, limit)
+		if err != nil {
+			log.Error("failed to get songs", "error", err)
+			resp.Fail(w, r, err, "failed to get songs")
+			return
+		}
+		if songs == nil {
+			songs = []models.Song{}
+		}
+
+		log.Info("songs retrieved successfully", slog.String("status", status), slog.Int("count", len(songs)))
+
+		render.JSON(w, r, songs)
+	}
+}
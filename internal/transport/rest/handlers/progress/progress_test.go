@@ -0,0 +1,51 @@
+package progress_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"song-lib/internal/transport/rest/handlers/progress"
+	"testing"
+)
+
+// statusGetter запоминает параметры последнего запроса песен по статусу
+type statusGetter struct {
+	status      string
+	page, limit int
+}
+
+func (g *statusGetter) GetSongsByStatus(status string, page, limit int) ([]models.Song, error) {
+	g.status, g.page, g.limit = status, page, limit
+	return nil, nil
+}
+
+// TestNewLimit - размер страницы по умолчанию и ограничение сверху
+func TestNewLimit(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		query string
+		page  int
+		limit int
+	}{
+		{query: "", page: 1, limit: resp.DefaultLimit},
+		{query: "?page=2&limit=20", page: 2, limit: 20},
+		{query: "?limit=1000000", page: 1, limit: resp.MaxLimit},
+	}
+
+	for _, tt := range tests {
+		getter := &statusGetter{}
+		w := httptest.NewRecorder()
+		progress.New(log, getter).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/enrichment"+tt.query, nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", tt.query, w.Code)
+		}
+		if getter.status != models.EnrichmentPending || getter.page != tt.page || getter.limit != tt.limit {
+			t.Errorf("%q: expected pending page %d limit %d, got %s page %d limit %d", tt.query, tt.page, tt.limit, getter.status, getter.page, getter.limit)
+		}
+	}
+}
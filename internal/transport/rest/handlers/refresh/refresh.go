@@ -0,0 +1,151 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"strconv"
+	"time"
+)
+
+type Response struct {
+	resp.Response
+	Song *models.Song `json:"song"`
+}
+
+type BulkResponse struct {
+	resp.Response
+	Queued int `json:"queued"`
+}
+
+type SongGetter interface {
+	GetSong(id int64) (*models.Song, error)
+}
+
+type SongRefresher interface {
+	Enrich(ctx context.Context, id int64, group, song string) error
+}
+
+type BulkRefresher interface {
+	RefreshByStatus(status string) (int, error)
+}
+
+// New re-enriches a single song. The external API is awaited for at most timeout, which must be
+// shorter than the server write timeout so the client always gets a response
+// @Summary Refresh song details
+// @Description Re-query the external API for the song and update its release date, text and link
+// @Tags Enrichment
+// @Param id path int true "Song ID"
+// @Produce  json
+// @Success 200 {object} refresh.Response
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song or song details not found"
+// @Failure 500 {object} resp.Problem "Failed to refresh song"
+// @Failure 502 {object} resp.Problem "External API unavailable"
+// @Failure 504 {object} resp.Problem "External API did not respond in time"
+// @Router /songs/{id}/refresh [post]
+func New(log *slog.Logger, getter SongGetter, refresher SongRefresher, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.refresh.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, errs.ErrValidation, "invalid song id")
+			return
+		}
+
+		song, err := getter.GetSong(id)
+		if err != nil {
+			log.Error("failed to get song", "error", err)
+			resp.Fail(w, r, err, "failed to get song")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		if err := refresher.Enrich(ctx, id, song.Group, song.Name); err != nil {
+			log.Error("failed to refresh song", "error", err)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = errs.Wrap(errs.ErrTimeout, "external API did not respond in time", err)
+			}
+			resp.Fail(w, r, err, "failed to refresh song")
+			return
+		}
+
+		song, err = getter.GetSong(id)
+		if err != nil {
+			log.Error("failed to get refreshed song", "error", err)
+			resp.Fail(w, r, err, "failed to get song")
+			return
+		}
+
+		log.Info("song refreshed successfully", slog.Int64("song_id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Song:     song,
+		})
+	}
+}
+
+// NewBulk queues songs with the given enrichment status for re-enrichment
+// @Summary Refresh songs by status
+// @Description Queue songs with the given enrichment status for re-enrichment in the background
+// @Tags Enrichment
+// @Param status query string false "Enrichment status" Enums(failed, done) default(failed)
+// @Produce  json
+// @Success 202 {object} refresh.BulkResponse
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 500 {object} resp.Problem "Failed to queue songs"
+// @Router /songs/refresh [post]
+func NewBulk(log *slog.Logger, refresher BulkRefresher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.refresh.NewBulk"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "":
+			status = models.EnrichmentFailed
+		case models.EnrichmentFailed, models.EnrichmentDone:
+		default:
+			log.Error("invalid enrichment status", slog.String("status", status))
+			resp.Fail(w, r, errs.ErrValidation, "status must be one of failed, done")
+			return
+		}
+
+		queued, err := refresher.RefreshByStatus(status)
+		if err != nil {
+			log.Error("failed to queue songs", "error", err)
+			resp.Fail(w, r, err, "failed to queue songs")
+			return
+		}
+
+		log.Info("songs queued for refresh", slog.String("status", status), slog.Int("count", queued))
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, BulkResponse{
+			Response: resp.OK(),
+			Queued:   queued,
+		})
+	}
+}
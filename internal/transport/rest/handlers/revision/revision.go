@@ -0,0 +1,257 @@
+package revision
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/author"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+type ListResponse struct {
+	Items []models.SongRevision `json:"items"`
+	Page  int                   `json:"page"`
+	Limit int                   `json:"limit"`
+	Next  string                `json:"next,omitempty"`
+}
+
+type RevisionLister interface {
+	GetSongRevisions(id int64, page, limit int) ([]models.SongRevision, bool, error)
+}
+
+type RevisionGetter interface {
+	GetSongRevision(id int64, revision int) (*models.SongRevision, error)
+}
+
+type RevisionDiffer interface {
+	DiffSongRevisions(id int64, from, to int) (*models.RevisionDiff, error)
+}
+
+type RevisionRestorer interface {
+	RestoreSongRevision(id int64, revision int, author string) (*models.Song, error)
+}
+
+// NewList gets revisions of the song
+// @Summary Get song revisions
+// @Description Get revisions of a song, newest first. Every change of group, name, release date,
+// @Description text or link creates a revision with the full state of the song, the first revision is the added song
+// @Tags Revisions
+// @Param id path int true "Song ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of revisions per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} revision.ListResponse
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 500 {object} resp.Problem "Failed to get revisions"
+// @Router /songs/{id}/revisions [get]
+func NewList(log *slog.Logger, lister RevisionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.revision.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "song id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		revisions, more, err := lister.GetSongRevisions(id, page, limit)
+		if err != nil {
+			log.Error("failed to get revisions", "error", err)
+			resp.Fail(w, r, err, "failed to get revisions")
+			return
+		}
+
+		response := ListResponse{Items: revisions, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.SongRevision{}
+		}
+
+		log.Info("revisions retrieved successfully", slog.Int64("song_id", id), slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
+
+// NewGet gets a single revision of the song
+// @Summary Get a song revision
+// @Description Get the state of a song after the change with the author and time of the change
+// @Tags Revisions
+// @Param id path int true "Song ID"
+// @Param rev path int true "Revision number"
+// @Produce  json
+// @Success 200 {object} models.SongRevision
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song or revision not found"
+// @Failure 500 {object} resp.Problem "Failed to get revision"
+// @Router /songs/{id}/revisions/{rev} [get]
+func NewGet(log *slog.Logger, getter RevisionGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.revision.NewGet"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "song id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+		rev, err := parseRevision(chi.URLParam(r, "rev"), "rev")
+		if err != nil {
+			log.Error("invalid revision", "error", err)
+			resp.Fail(w, r, err, "invalid revision")
+			return
+		}
+
+		revision, err := getter.GetSongRevision(id, rev)
+		if err != nil {
+			log.Error("failed to get revision", "error", err)
+			resp.Fail(w, r, err, "failed to get revision")
+			return
+		}
+
+		log.Info("revision retrieved successfully", slog.Int64("song_id", id), slog.Int("revision", rev))
+
+		render.JSON(w, r, revision)
+	}
+}
+
+// NewDiff compares two revisions of the song
+// @Summary Compare song revisions
+// @Description Get the fields changed between two revisions and a line diff of the text.
+// @Description text is empty if the text has not changed
+// @Tags Revisions
+// @Param id path int true "Song ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Produce  json
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song or revision not found"
+// @Failure 500 {object} resp.Problem "Failed to compare revisions"
+// @Router /songs/{id}/revisions/diff [get]
+func NewDiff(log *slog.Logger, differ RevisionDiffer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.revision.NewDiff"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "song id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+		from, err := parseRevision(r.URL.Query().Get("from"), "from")
+		if err != nil {
+			log.Error("invalid revision", "error", err)
+			resp.Fail(w, r, err, "invalid revision")
+			return
+		}
+		to, err := parseRevision(r.URL.Query().Get("to"), "to")
+		if err != nil {
+			log.Error("invalid revision", "error", err)
+			resp.Fail(w, r, err, "invalid revision")
+			return
+		}
+
+		diff, err := differ.DiffSongRevisions(id, from, to)
+		if err != nil {
+			log.Error("failed to compare revisions", "error", err)
+			resp.Fail(w, r, err, "failed to compare revisions")
+			return
+		}
+
+		log.Info("revisions compared successfully", slog.Int64("song_id", id), slog.Int("from", from), slog.Int("to", to))
+
+		render.JSON(w, r, diff)
+	}
+}
+
+// NewRestore rolls the song back to the revision
+// @Summary Restore a song revision
+// @Description Set group, name, release date, text and link of a song from the revision.
+// @Description The rollback is saved as a new revision, enrichment no longer changes the restored fields
+// @Tags Revisions
+// @Param id path int true "Song ID"
+// @Param rev path int true "Revision number"
+// @Param X-Author header string false "Author of the change, saved in the revision"
+// @Produce  json
+// @Success 200 {object} models.Song
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song or revision not found"
+// @Failure 409 {object} resp.Problem "Restored values conflict with the current data"
+// @Failure 500 {object} resp.Problem "Failed to restore revision"
+// @Router /songs/{id}/revisions/{rev}/restore [post]
+func NewRestore(log *slog.Logger, restorer RevisionRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.revision.NewRestore"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "song id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+		rev, err := parseRevision(chi.URLParam(r, "rev"), "rev")
+		if err != nil {
+			log.Error("invalid revision", "error", err)
+			resp.Fail(w, r, err, "invalid revision")
+			return
+		}
+		changedBy, err := author.FromRequest(r)
+		if err != nil {
+			log.Error("invalid author", "error", err)
+			resp.Fail(w, r, err, "invalid author")
+			return
+		}
+
+		song, err := restorer.RestoreSongRevision(id, rev, changedBy)
+		if err != nil {
+			log.Error("failed to restore revision", "error", err)
+			resp.Fail(w, r, err, "failed to restore revision")
+			return
+		}
+
+		log.Info("revision restored successfully", slog.Int64("song_id", id), slog.Int("revision", rev))
+
+		render.JSON(w, r, song)
+	}
+}
+
+func parseRevision(value, param string) (int, error) {
+	rev, err := strconv.Atoi(value)
+	if err != nil || rev <= 0 {
+		return 0, errs.New(errs.ErrValidation, param+" must be a positive revision number")
+	}
+	return rev, nil
+}
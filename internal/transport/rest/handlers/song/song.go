@@ -0,0 +1,58 @@
+package song
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+type SongGetter interface {
+	GetSong(id int64) (*models.Song, error)
+}
+
+// New gets a single song by its ID
+// @Summary Get a song
+// @Description Get a song with all its details by its ID
+// @Tags Songs
+// @Param id path int true "Song ID"
+// @Produce  json
+// @Success 200 {object} models.Song
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 500 {object} resp.Problem "Failed to get song"
+// @Router /songs/{id} [get]
+func New(log *slog.Logger, getter SongGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.song.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, errs.ErrValidation, "invalid song id")
+			return
+		}
+
+		song, err := getter.GetSong(id)
+		if err != nil {
+			log.Error("failed to get song", "error", err)
+			resp.Fail(w, r, err, "failed to get song")
+			return
+		}
+
+		log.Info("song retrieved successfully", slog.Int64("song_id", id))
+
+		render.JSON(w, r, song)
+	}
+}
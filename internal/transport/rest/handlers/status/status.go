@@ -0,0 +1,45 @@
+package status
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/enrichment"
+	"song-lib/internal/lib/resp"
+)
+
+type Response struct {
+	resp.Response
+	Providers []enrichment.ProviderStatus `json:"providers"`
+}
+
+type ProviderStatuser interface {
+	Status() []enrichment.ProviderStatus
+}
+
+// New returns the state of the external API circuit breakers
+// @Summary Get external API status
+// @Description Get the circuit breaker state of every external song details provider
+// @Tags Enrichment
+// @Produce  json
+// @Success 200 {object} status.Response
+// @Router /enrichment/status [get]
+func New(log *slog.Logger, providers ProviderStatuser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.status.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		statuses := providers.Status()
+		log.Debug("provider statuses retrieved", slog.Int("count", len(statuses)))
+
+		render.JSON(w, r, Response{
+			Response:  resp.OK(),
+			Providers: statuses,
+		})
+	}
+}
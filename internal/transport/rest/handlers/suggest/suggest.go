@@ -0,0 +1,107 @@
+package suggest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"strings"
+	"time"
+)
+
+// Число подсказок по умолчанию и максимальное
+const (
+	DefaultLimit = 10
+	MaxLimit     = 50
+)
+
+type Response struct {
+	Field string              `json:"field"`
+	Items []models.Suggestion `json:"items"`
+}
+
+type Suggester interface {
+	SuggestSongs(field, prefix string, limit int) ([]models.Suggestion, error)
+}
+
+// New suggests groups and song names for typeahead
+// @Summary Autocomplete groups and song names
+// @Description Get distinct groups or song names starting with the prefix (case-insensitive), most frequent first,
+// @Description with the number of songs for each. Responses are cacheable: Cache-Control allows clients and proxies
+// @Description to reuse them for a short time and an ETag lets them revalidate with If-None-Match
+// @Tags Songs
+// @Param field query string true "Field to complete" Enums(group, name)
+// @Param prefix query string true "Beginning of the value"
+// @Param limit query int false "Number of suggestions, at most 50" default(10)
+// @Produce  json
+// @Success 200 {object} suggest.Response
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} Cache-Control "public, max-age=<SUGGEST_MAX_AGE>"
+// @Header 200 {string} ETag "Hash of the response body"
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 500 {object} resp.Problem "Failed to get suggestions"
+// @Router /suggest [get]
+func New(log *slog.Logger, suggester Suggester, maxAge time.Duration) http.HandlerFunc {
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.suggest.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		field := r.URL.Query().Get("field")
+		prefix := r.URL.Query().Get("prefix")
+		limit := resp.Limit(r.URL.Query(), DefaultLimit, MaxLimit)
+
+		suggestions, err := suggester.SuggestSongs(field, prefix, limit)
+		if err != nil {
+			log.Error("failed to get suggestions", "error", err)
+			resp.Fail(w, r, err, "failed to get suggestions")
+			return
+		}
+		if suggestions == nil {
+			suggestions = []models.Suggestion{}
+		}
+
+		body, err := json.Marshal(Response{Field: field, Items: suggestions})
+		if err != nil {
+			log.Error("failed to encode suggestions", "error", err)
+			resp.Fail(w, r, err, "failed to get suggestions")
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `W/"` + base64.RawURLEncoding.EncodeToString(sum[:12]) + `"`
+
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", etag)
+		if matchETag(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		log.Debug("suggestions found", slog.String("field", field), slog.Int("count", len(suggestions)))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}
+}
+
+// matchETag проверяет If-None-Match: список меток через запятую или *
+func matchETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package tag
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/errs"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
+	"song-lib/internal/models"
+)
+
+// Число тегов в облаке по умолчанию и максимальное
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Request - новый набор тегов песни, пустой список удаляет все теги
+type Request struct {
+	Tags []string `json:"tags" validate:"required,max=20,dive,notblank,max=64"`
+}
+
+type CloudResponse struct {
+	Items []models.TagCount `json:"items"`
+}
+
+type TagSetter interface {
+	SetSongTags(id int64, tags []string) (*models.Song, error)
+}
+
+type TagCloudGetter interface {
+	GetTagCloud(limit int) ([]models.TagCount, error)
+}
+
+// NewSet replaces the tags of the song
+// @Summary Set song tags
+// @Description Replace the tags of a song: missing tags are attached, tags not in the list are detached.
+// @Description Tags are case-insensitive and stored in lower case, an empty list removes all tags
+// @Tags Tags
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Param tags body tag.Request true "New tags of the song"
+// @Success 200 {object} models.Song
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 500 {object} resp.Problem "Failed to set tags"
+// @Router /songs/{id}/tags [put]
+func NewSet(log *slog.Logger, setter TagSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.tag.NewSet"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "song id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			resp.Fail(w, r, errs.ErrValidation, "failed to decode request")
+			return
+		}
+
+		if err := validation.Struct(req); err != nil {
+			log.Error("invalid request", "error", err)
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		song, err := setter.SetSongTags(id, req.Tags)
+		if err != nil {
+			log.Error("failed to set tags", "error", err)
+			resp.Fail(w, r, err, "failed to set tags")
+			return
+		}
+
+		log.Info("tags set successfully", slog.Int64("song_id", id), slog.Int("count", len(song.Tags)))
+
+		render.JSON(w, r, song)
+	}
+}
+
+// NewCloud gets the most used tags
+// @Summary Get tag cloud
+// @Description Get the most used tags with the number of songs, most used first
+// @Tags Tags
+// @Param limit query int false "Number of tags, at most 200" default(50)
+// @Produce  json
+// @Success 200 {object} tag.CloudResponse
+// @Failure 500 {object} resp.Problem "Failed to get tags"
+// @Router /tags [get]
+func NewCloud(log *slog.Logger, getter TagCloudGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.tag.NewCloud"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		limit := resp.Limit(r.URL.Query(), DefaultLimit, MaxLimit)
+
+		tags, err := getter.GetTagCloud(limit)
+		if err != nil {
+			log.Error("failed to get tags", "error", err)
+			resp.Fail(w, r, err, "failed to get tags")
+			return
+		}
+		if tags == nil {
+			tags = []models.TagCount{}
+		}
+
+		log.Info("tags retrieved successfully", slog.Int("count", len(tags)))
+
+		render.JSON(w, r, CloudResponse{Items: tags})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-chi/render"
 	"log/slog"
 	"net/http"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/lib/resp"
 	"song-lib/internal/models"
 	"strconv"
@@ -32,15 +33,15 @@ type SongTextGetter interface {
 // @Param limit query int false "Number of verses per page" default(3)
 // @Produce  json
 // @Success 200 {object} text.Response
-// @Failure 400 {object} resp.Response "Invalid request"
-// @Failure 404 {object} resp.Response "Song not found"
-// @Failure 500 {object} resp.Response "Failed to get song text"
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 500 {object} resp.Problem "Failed to get song text"
 // @Router /songs/{id}/text [get]
 func New(log *slog.Logger, getter SongTextGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "internal.transport.handlers.text.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -49,14 +50,14 @@ func New(log *slog.Logger, getter SongTextGetter) http.HandlerFunc {
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil || id <= 0 {
 			log.Error("invalid song id", "error", err)
-			render.JSON(w, r, resp.Error("invalid song id"))
+			resp.Fail(w, r, errs.ErrValidation, "invalid song id")
 			return
 		}
 
 		song, err := getter.GetSongText(id)
 		if err != nil {
 			log.Error("failed to get song text", "error", err)
-			render.JSON(w, r, resp.Error("failed to get song text"))
+			resp.Fail(w, r, err, "failed to get song text")
 			return
 		}
 
@@ -66,7 +67,7 @@ func New(log *slog.Logger, getter SongTextGetter) http.HandlerFunc {
 
 		start := (page - 1) * limit
 		if start >= len(verses) {
-			render.JSON(w, r, resp.Error("no verses found for this page"))
+			resp.Fail(w, r, errs.ErrNotFound, "no verses found for this page")
 			return
 		}
 
@@ -0,0 +1,109 @@
+package trash
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"song-lib/internal/lib/resp"
+	"song-lib/internal/models"
+	"strconv"
+)
+
+type ListResponse struct {
+	Items []models.Song `json:"items"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Next  string        `json:"next,omitempty"`
+}
+
+type TrashLister interface {
+	GetTrash(page, limit int) ([]models.Song, bool, error)
+}
+
+type SongRestorer interface {
+	RestoreSong(id int64) (*models.Song, error)
+}
+
+// NewList gets songs from the trash
+// @Summary Get trash
+// @Description Get deleted songs, recently deleted first. Songs are purged from the trash after the retention period
+// @Tags Trash
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of songs per page, at most 100" default(10)
+// @Produce  json
+// @Success 200 {object} trash.ListResponse
+// @Failure 500 {object} resp.Problem "Failed to get trash"
+// @Router /trash [get]
+func NewList(log *slog.Logger, lister TrashLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.trash.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		page, limit := resp.Pagination(r.URL.Query())
+
+		songs, more, err := lister.GetTrash(page, limit)
+		if err != nil {
+			log.Error("failed to get trash", "error", err)
+			resp.Fail(w, r, err, "failed to get trash")
+			return
+		}
+
+		response := ListResponse{Items: songs, Page: page, Limit: limit}
+		if more {
+			response.Next = resp.PageURL(r, map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if response.Items == nil {
+			response.Items = []models.Song{}
+		}
+
+		log.Info("trash retrieved successfully", slog.Int("count", len(response.Items)))
+
+		resp.SetLinks(w, resp.Link{Rel: "next", URL: response.Next})
+		render.JSON(w, r, response)
+	}
+}
+
+// NewRestore restores a song from the trash
+// @Summary Restore a song
+// @Description Restore a deleted song with its tags and its places in the album and playlists
+// @Tags Trash
+// @Param id path int true "Song ID"
+// @Produce  json
+// @Success 200 {object} models.Song
+// @Failure 400 {object} resp.Problem "Invalid request"
+// @Failure 404 {object} resp.Problem "Song not found in trash"
+// @Failure 500 {object} resp.Problem "Failed to restore song"
+// @Router /songs/{id}/restore [post]
+func NewRestore(log *slog.Logger, restorer SongRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "internal.transport.handlers.trash.NewRestore"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := resp.PathID(r, "id", "song id")
+		if err != nil {
+			log.Error("invalid song id", "error", err)
+			resp.Fail(w, r, err, "invalid song id")
+			return
+		}
+
+		song, err := restorer.RestoreSong(id)
+		if err != nil {
+			log.Error("failed to restore song", "error", err)
+			resp.Fail(w, r, err, "failed to restore song")
+			return
+		}
+
+		log.Info("song restored successfully", slog.Int64("song_id", id))
+
+		render.JSON(w, r, song)
+	}
+}
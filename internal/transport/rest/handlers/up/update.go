@@ -4,20 +4,22 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
+	"song-lib/internal/lib/author"
+	"song-lib/internal/lib/errs"
 	"song-lib/internal/lib/resp"
+	"song-lib/internal/lib/validation"
 	"song-lib/internal/models"
 	"strconv"
 )
 
 type Request struct {
-	Group       string `json:"group" validate:"required"`
-	Name        string `json:"song" validate:"required"`
-	ReleaseDate string `json:"release_date"`
+	Group       string `json:"group" validate:"required,notblank,max=255"`
+	Name        string `json:"song" validate:"required,notblank,max=255"`
+	ReleaseDate string `json:"release_date" validate:"omitempty,songdate"`
 	Text        string `json:"text"`
-	Link        string `json:"link"`
+	Link        string `json:"link" validate:"omitempty,url,max=255"`
 }
 
 type Response struct {
@@ -26,25 +28,27 @@ type Response struct {
 }
 
 type SongUpdater interface {
-	UpdateSong(song *models.Song) (int64, error)
+	UpdateSong(song *models.Song, author string) (int64, error)
 }
 
 // New changes the song in the library
 // @Summary Update a song
-// @Description Update a song in the library by its ID
+// @Description Update a song in the library by its ID. The change is saved as a new revision of the song
 // @Tags Songs
 // @Param id path int true "Song ID"
+// @Param X-Author header string false "Author of the change, saved in the revision"
 // @Param song body up.Request true "Updated song details"
 // @Produce  json
-// @Success 200 {object} resp.Response
-// @Failure 404 {object} resp.Response "Song not found"
-// @Failure 500 {object} resp.Response "Failed to update song"
+// @Success 200 {object} up.Response
+// @Failure 400 {object} resp.Problem "Invalid request, failed fields are listed in errors"
+// @Failure 404 {object} resp.Problem "Song not found"
+// @Failure 500 {object} resp.Problem "Failed to update song"
 // @Router /songs/{id} [put]
 func New(log *slog.Logger, updater SongUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "internal.transport.handlers.update.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -53,7 +57,7 @@ func New(log *slog.Logger, updater SongUpdater) http.HandlerFunc {
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil || id <= 0 {
 			log.Error("invalid song id", "error", err)
-			render.JSON(w, r, resp.Error("invalid song id"))
+			resp.Fail(w, r, errs.ErrValidation, "invalid song id")
 			return
 		}
 
@@ -61,13 +65,20 @@ func New(log *slog.Logger, updater SongUpdater) http.HandlerFunc {
 		err = render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			log.Error("failed to decode request body", "error", err)
-			render.JSON(w, r, resp.Error("failed to decode request"))
+			resp.Fail(w, r, errs.ErrValidation, "failed to decode request")
 			return
 		}
 
-		if err := validator.New().Struct(req); err != nil {
+		if err := validation.Struct(req); err != nil {
 			log.Error("invalid request", "error", err)
-			render.JSON(w, r, resp.Error("invalid request: missing or invalid group and song"))
+			resp.Fail(w, r, err, "invalid request")
+			return
+		}
+
+		changedBy, err := author.FromRequest(r)
+		if err != nil {
+			log.Error("invalid author", "error", err)
+			resp.Fail(w, r, err, "invalid author")
 			return
 		}
 
@@ -80,16 +91,9 @@ func New(log *slog.Logger, updater SongUpdater) http.HandlerFunc {
 			Link:        req.Link,
 		}
 
-		rowsAffected, err := updater.UpdateSong(updatedSong)
-		if err != nil {
+		if _, err := updater.UpdateSong(updatedSong, changedBy); err != nil {
 			log.Error("failed to update song", "error", err)
-			render.JSON(w, r, resp.Error("failed to update song"))
-			return
-		}
-
-		if rowsAffected == 0 {
-			log.Error("song not found", slog.Int64("song_id", id))
-			render.JSON(w, r, resp.Error("song not found"))
+			resp.Fail(w, r, err, "failed to update song")
 			return
 		}
 
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"song-lib/internal/config"
+	"time"
+)
+
+type CachePurger interface {
+	PurgeCachedDetails(before time.Time, limit int) (int64, error)
+}
+
+// CacheCleaner периодически удаляет устаревшие записи кэша ответов внешнего API
+type CacheCleaner struct {
+	log       *slog.Logger
+	purger    CachePurger
+	interval  time.Duration
+	batchSize int
+}
+
+func NewCacheCleaner(log *slog.Logger, purger CachePurger, cfg config.Cache) *CacheCleaner {
+	return &CacheCleaner{
+		log:       log,
+		purger:    purger,
+		interval:  cfg.PurgeInterval,
+		batchSize: cfg.PurgeBatchSize,
+	}
+}
+
+// Run очищает кэш раз в interval до отмены ctx. Нулевой interval отключает очистку
+func (c *CacheCleaner) Run(ctx context.Context) {
+	if c.interval <= 0 || c.batchSize <= 0 {
+		c.log.Info("cache cleaner disabled")
+		return
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.tick(ctx)
+		}
+	}
+}
+
+// tick удаляет порции по batchSize, пока в кэше есть устаревшие записи
+func (c *CacheCleaner) tick(ctx context.Context) {
+	const op = "internal.worker.CacheCleaner.tick"
+
+	log := c.log.With(slog.String("op", op))
+
+	now := time.Now()
+	var purged int64
+	for ctx.Err() == nil {
+		count, err := c.purger.PurgeCachedDetails(now, c.batchSize)
+		if err != nil {
+			log.Error("failed to purge cache", "error", err)
+			break
+		}
+		purged += count
+		if count < int64(c.batchSize) {
+			break
+		}
+	}
+
+	if purged > 0 {
+		log.Info("expired cache entries purged", slog.Int64("count", purged))
+	}
+}
@@ -0,0 +1,314 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/enrichment"
+	"song-lib/internal/models"
+	"sync"
+	"time"
+)
+
+// Job - песня, ожидающая обогащения данными внешнего API
+type Job struct {
+	ID    int64
+	Group string
+	Song  string
+}
+
+type SongEnricher interface {
+	SongDetails(ctx context.Context, group, song string) (*models.SongDetails, error)
+}
+
+type SongStore interface {
+	GetSongsByStatus(status string, page, limit int) ([]models.Song, error)
+	UpdateSongDetails(id int64, details *models.SongDetails) (int64, error)
+	SetEnrichmentFailed(id int64, permanent bool) (int64, error)
+}
+
+// Pool - пул фоновых обработчиков, запрашивающих у внешнего API детали песен
+type Pool struct {
+	log        *slog.Logger
+	enricher   SongEnricher
+	store      SongStore
+	workers    int
+	jobTimeout time.Duration
+	resume     time.Duration
+
+	jobs   chan Job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+
+	// queued - песни в очереди или в обработке, повторно они в очередь не ставятся
+	queuedMu sync.Mutex
+	queued   map[int64]struct{}
+}
+
+func NewPool(log *slog.Logger, enricher SongEnricher, store SongStore, cfg config.Worker) *Pool {
+	workers := cfg.Count
+	if workers < 1 {
+		workers = 1
+	}
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Pool{
+		log:        log,
+		enricher:   enricher,
+		store:      store,
+		workers:    workers,
+		jobTimeout: cfg.JobTimeout,
+		resume:     cfg.ResumeInterval,
+		jobs:       make(chan Job, queueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		queued:     make(map[int64]struct{}),
+	}
+}
+
+// Start запускает обработчики и ставит в очередь песни, оставшиеся в статусе pending
+// после предыдущего запуска. Песни pending, не поместившиеся в очередь, ставятся
+// в неё раз в resume, пока пул не остановлен
+func (p *Pool) Start() error {
+	const op = "internal.worker.Pool.Start"
+
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.run()
+	}
+
+	resumed, err := p.RefreshByStatus(models.EnrichmentPending)
+	if err != nil {
+		return fmt.Errorf("%s: get pending songs: %w", op, err)
+	}
+	p.log.Info("enrichment workers started", slog.Int("workers", p.workers), slog.Int("resumed", resumed))
+
+	if p.resume > 0 {
+		go p.resumePending()
+	}
+
+	return nil
+}
+
+// resumePending ставит в очередь песни pending раз в resume до остановки пула
+func (p *Pool) resumePending() {
+	const op = "internal.worker.Pool.resumePending"
+
+	log := p.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(p.resume)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			if p.isClosed() {
+				return
+			}
+			queued, err := p.RefreshByStatus(models.EnrichmentPending)
+			if err != nil {
+				log.Error("failed to queue pending songs", "error", err)
+				continue
+			}
+			if queued > 0 {
+				log.Info("pending songs queued", slog.Int("count", queued))
+			}
+		}
+	}
+}
+
+func (p *Pool) isClosed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.closed
+}
+
+// Enqueue ставит песню в очередь без блокировки. Песня, которая уже в очереди или
+// в обработке, повторно не ставится, но считается поставленной. Если очередь переполнена,
+// песня остаётся в статусе pending и будет поставлена в очередь позже (см. Start),
+// если пул остановлен - после перезапуска
+func (p *Pool) Enqueue(id int64, group, song string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.log.Warn("enrichment pool is stopped, job skipped", slog.Int64("song_id", id))
+		return false
+	}
+
+	p.queuedMu.Lock()
+	defer p.queuedMu.Unlock()
+
+	if _, ok := p.queued[id]; ok {
+		return true
+	}
+
+	select {
+	case p.jobs <- Job{ID: id, Group: group, Song: song}:
+		p.queued[id] = struct{}{}
+		return true
+	default:
+		p.log.Warn("enrichment queue is full, job skipped", slog.Int64("song_id", id))
+		return false
+	}
+}
+
+// isQueued сообщает, стоит ли песня в очереди или обрабатывается
+func (p *Pool) isQueued(id int64) bool {
+	p.queuedMu.Lock()
+	defer p.queuedMu.Unlock()
+
+	_, ok := p.queued[id]
+	return ok
+}
+
+func (p *Pool) done(id int64) {
+	p.queuedMu.Lock()
+	defer p.queuedMu.Unlock()
+
+	delete(p.queued, id)
+}
+
+// Stop перестаёт принимать задания и ждёт обработки очереди. Если ctx истекает раньше,
+// текущие запросы к внешнему API отменяются
+func (p *Pool) Stop(ctx context.Context) error {
+	const op = "internal.worker.Pool.Stop"
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		p.log.Info("enrichment workers stopped")
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+}
+
+func (p *Pool) run() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		// После отмены оставшиеся задания не обрабатываются и остаются в статусе pending
+		if p.ctx.Err() == nil {
+			p.process(job)
+		}
+		p.done(job.ID)
+	}
+}
+
+func (p *Pool) process(job Job) {
+	const op = "internal.worker.Pool.process"
+
+	log := p.log.With(slog.String("op", op), slog.Int64("song_id", job.ID))
+
+	ctx := p.ctx
+	if p.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.jobTimeout)
+		defer cancel()
+	}
+
+	if err := p.Enrich(ctx, job.ID, job.Group, job.Song); err != nil {
+		if p.ctx.Err() != nil {
+			log.Warn("enrichment interrupted by shutdown", "error", err)
+			return
+		}
+		log.Error("failed to enrich song", "error", err)
+		return
+	}
+
+	log.Info("song enriched successfully")
+}
+
+// Enrich синхронно запрашивает детали песни и сохраняет их. Если внешнее API не знает песню
+// или ответило некорректно, песня помечается как failed. При временной недоступности API
+// failed ставится только песне, ещё не получавшей детали, обновляемая песня остаётся done
+// со старыми данными. Отменённый ctx статус не меняет
+func (p *Pool) Enrich(ctx context.Context, id int64, group, song string) error {
+	const op = "internal.worker.Pool.Enrich"
+
+	details, err := p.enricher.SongDetails(ctx, group, song)
+	if err != nil {
+		if ctx.Err() == nil {
+			permanent := errors.Is(err, enrichment.ErrNotFound) || errors.Is(err, enrichment.ErrBadResponse)
+			if _, err := p.store.SetEnrichmentFailed(id, permanent); err != nil {
+				p.log.Error("failed to mark song as failed", slog.Int64("song_id", id), "error", err)
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := p.store.UpdateSongDetails(id, details); err != nil {
+		return fmt.Errorf("%s: update song details: %w", op, err)
+	}
+
+	return nil
+}
+
+// RefreshByStatus ставит в очередь песни с указанным статусом, сколько помещается
+// в свободное место очереди, и возвращает их число. Песни, которые уже в очереди
+// или в обработке, пропускаются, и берётся следующая страница
+func (p *Pool) RefreshByStatus(status string) (int, error) {
+	const op = "internal.worker.Pool.RefreshByStatus"
+
+	free := cap(p.jobs) - len(p.jobs)
+	if free <= 0 {
+		return 0, nil
+	}
+
+	queued := 0
+	for page := 1; queued < free; page++ {
+		songs, err := p.store.GetSongsByStatus(status, page, free)
+		if err != nil {
+			return queued, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, song := range songs {
+			if queued == free {
+				break
+			}
+			if p.isQueued(song.ID) {
+				continue
+			}
+			if !p.Enqueue(song.ID, song.Group, song.Name) {
+				return queued, nil
+			}
+			queued++
+		}
+
+		if len(songs) < free {
+			break
+		}
+	}
+
+	return queued, nil
+}
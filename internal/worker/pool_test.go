@@ -0,0 +1,175 @@
+package worker_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/enrichment"
+	"song-lib/internal/models"
+	"song-lib/internal/worker"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubEnricher struct{}
+
+func (stubEnricher) SongDetails(_ context.Context, _, song string) (*models.SongDetails, error) {
+	switch song {
+	case "Unknown":
+		return nil, enrichment.ErrNotFound
+	case "Down":
+		return nil, enrichment.ErrUnavailable
+	}
+	time.Sleep(5 * time.Millisecond)
+	return &models.SongDetails{Text: song + " lyrics"}, nil
+}
+
+// stubStore запоминает статусы песен в памяти
+type stubStore struct {
+	mu       sync.Mutex
+	pending  []models.Song
+	statuses map[int64]string
+}
+
+// GetSongsByStatus отдаёт страницу песен из pending, кроме песен с другим сохранённым статусом
+func (s *stubStore) GetSongsByStatus(status string, page, limit int) ([]models.Song, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var songs []models.Song
+	for _, song := range s.pending {
+		if saved, ok := s.statuses[song.ID]; !ok || saved == status {
+			songs = append(songs, song)
+		}
+	}
+	start := min((page-1)*limit, len(songs))
+	return songs[start:min(start+limit, len(songs))], nil
+}
+
+func (s *stubStore) UpdateSongDetails(id int64, _ *models.SongDetails) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[id] = models.EnrichmentDone
+	return 1, nil
+}
+
+func (s *stubStore) SetEnrichmentFailed(id int64, permanent bool) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !permanent && s.statuses[id] == models.EnrichmentDone {
+		return 0, nil
+	}
+	s.statuses[id] = models.EnrichmentFailed
+	return 1, nil
+}
+
+// TestPool - обработка очереди, возобновление pending песен и корректная остановка
+func TestPool(t *testing.T) {
+	store := &stubStore{
+		pending:  []models.Song{{ID: 1, Group: "Muse", Name: "Uprising"}},
+		statuses: map[int64]string{},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := worker.NewPool(log, stubEnricher{}, store, config.Worker{Count: 2, QueueSize: 10, JobTimeout: time.Second})
+
+	if err := pool.Start(); err != nil {
+		t.Fatalf("failed to start pool: %v", err)
+	}
+	pool.Enqueue(2, "Muse", "Starlight")
+	pool.Enqueue(3, "Muse", "Unknown")
+
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("failed to stop pool: %v", err)
+	}
+
+	expected := map[int64]string{1: models.EnrichmentDone, 2: models.EnrichmentDone, 3: models.EnrichmentFailed}
+	for id, status := range expected {
+		if store.statuses[id] != status {
+			t.Errorf("expected song %d status %s, got %s", id, status, store.statuses[id])
+		}
+	}
+
+	if pool.Enqueue(4, "Muse", "Madness") {
+		t.Errorf("expected stopped pool to reject jobs")
+	}
+
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Errorf("expected repeated stop to succeed, got %v", err)
+	}
+}
+
+// TestPoolEnrichUnavailable - временная недоступность API не портит уже обогащённую песню
+func TestPoolEnrichUnavailable(t *testing.T) {
+	store := &stubStore{statuses: map[int64]string{1: models.EnrichmentDone, 2: models.EnrichmentPending}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := worker.NewPool(log, stubEnricher{}, store, config.Worker{Count: 1, QueueSize: 10})
+
+	for _, id := range []int64{1, 2} {
+		if err := pool.Enrich(context.Background(), id, "Muse", "Down"); err == nil {
+			t.Errorf("expected song %d enrichment to fail", id)
+		}
+	}
+
+	expected := map[int64]string{1: models.EnrichmentDone, 2: models.EnrichmentFailed}
+	for id, status := range expected {
+		if store.statuses[id] != status {
+			t.Errorf("expected song %d status %s, got %s", id, status, store.statuses[id])
+		}
+	}
+}
+
+// TestPoolRefreshByStatus - песни, уже стоящие в очереди, повторно не ставятся
+func TestPoolRefreshByStatus(t *testing.T) {
+	store := &stubStore{
+		pending:  []models.Song{{ID: 1, Group: "Muse", Name: "Uprising"}, {ID: 2, Group: "Muse", Name: "Starlight"}},
+		statuses: map[int64]string{},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	// Пул не запущен, поэтому задания остаются в очереди
+	pool := worker.NewPool(log, stubEnricher{}, store, config.Worker{Count: 1, QueueSize: 4})
+
+	queued, err := pool.RefreshByStatus(models.EnrichmentFailed)
+	if err != nil || queued != 2 {
+		t.Fatalf("expected 2 queued songs, got %d: %v", queued, err)
+	}
+
+	queued, err = pool.RefreshByStatus(models.EnrichmentFailed)
+	if err != nil || queued != 0 {
+		t.Errorf("expected queued songs to be skipped, got %d: %v", queued, err)
+	}
+}
+
+// TestPoolResumePending - песни pending, не поместившиеся в очередь, ставятся в неё позже
+func TestPoolResumePending(t *testing.T) {
+	store := &stubStore{
+		pending: []models.Song{
+			{ID: 1, Group: "Muse", Name: "Uprising"},
+			{ID: 2, Group: "Muse", Name: "Starlight"},
+			{ID: 3, Group: "Muse", Name: "Madness"},
+		},
+		statuses: map[int64]string{},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := worker.NewPool(log, stubEnricher{}, store, config.Worker{Count: 1, QueueSize: 1, ResumeInterval: 5 * time.Millisecond})
+
+	if err := pool.Start(); err != nil {
+		t.Fatalf("failed to start pool: %v", err)
+	}
+	defer pool.Stop(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		store.mu.Lock()
+		enriched := len(store.statuses)
+		store.mu.Unlock()
+		if enriched == len(store.pending) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d enriched songs, got %d", len(store.pending), enriched)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
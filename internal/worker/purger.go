@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"song-lib/internal/config"
+	"time"
+)
+
+type SongPurger interface {
+	PurgeSongs(before time.Time, limit int) (int64, error)
+}
+
+// Purger периодически удаляет навсегда песни, которые лежат в корзине дольше retention
+type Purger struct {
+	log       *slog.Logger
+	purger    SongPurger
+	interval  time.Duration
+	retention time.Duration
+	batchSize int
+}
+
+func NewPurger(log *slog.Logger, purger SongPurger, cfg config.Trash) *Purger {
+	return &Purger{
+		log:       log,
+		purger:    purger,
+		interval:  cfg.PurgeInterval,
+		retention: cfg.Retention,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+// Run очищает корзину раз в interval до отмены ctx. Нулевой interval отключает очистку
+func (p *Purger) Run(ctx context.Context) {
+	if p.interval <= 0 || p.batchSize <= 0 {
+		p.log.Info("trash purger disabled")
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.tick(ctx)
+		}
+	}
+}
+
+// tick удаляет порции по batchSize, пока в корзине есть просроченные песни,
+// чтобы одна транзакция не блокировала много строк
+func (p *Purger) tick(ctx context.Context) {
+	const op = "internal.worker.Purger.tick"
+
+	log := p.log.With(slog.String("op", op))
+
+	before := time.Now().Add(-p.retention)
+	var purged int64
+	for ctx.Err() == nil {
+		count, err := p.purger.PurgeSongs(before, p.batchSize)
+		if err != nil {
+			log.Error("failed to purge trash", "error", err)
+			break
+		}
+		purged += count
+		if count < int64(p.batchSize) {
+			break
+		}
+	}
+
+	if purged > 0 {
+		log.Info("songs purged from trash", slog.Int64("count", purged))
+	}
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"song-lib/internal/config"
+	"song-lib/internal/models"
+	"time"
+)
+
+type StaleSongLister interface {
+	GetStaleSongs(before time.Time, limit int) ([]models.Song, error)
+}
+
+type Enqueuer interface {
+	Enqueue(id int64, group, song string) bool
+}
+
+// Scheduler периодически ставит в очередь песни, данные которых старше maxAge
+type Scheduler struct {
+	log       *slog.Logger
+	lister    StaleSongLister
+	queue     Enqueuer
+	interval  time.Duration
+	maxAge    time.Duration
+	batchSize int
+}
+
+func NewScheduler(log *slog.Logger, lister StaleSongLister, queue Enqueuer, cfg config.Refresh) *Scheduler {
+	return &Scheduler{
+		log:       log,
+		lister:    lister,
+		queue:     queue,
+		interval:  cfg.Interval,
+		maxAge:    cfg.MaxAge,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+// Run выполняет обновление раз в interval до отмены ctx. Нулевой interval отключает планировщик
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.interval <= 0 || s.batchSize <= 0 {
+		s.log.Info("refresh scheduler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+func (s *Scheduler) tick() {
+	const op = "internal.worker.Scheduler.tick"
+
+	log := s.log.With(slog.String("op", op))
+
+	songs, err := s.lister.GetStaleSongs(time.Now().Add(-s.maxAge), s.batchSize)
+	if err != nil {
+		log.Error("failed to get stale songs", "error", err)
+		return
+	}
+
+	queued := 0
+	for _, song := range songs {
+		if !s.queue.Enqueue(song.ID, song.Group, song.Name) {
+			break
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		log.Info("stale songs queued for refresh", slog.Int("count", queued))
+	}
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Песни, добавленные до асинхронного обогащения, уже содержат данные внешнего API
+ALTER TABLE songs
+    ADD COLUMN enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done'
+        CHECK (enrichment_status IN ('pending', 'done', 'failed'));
+ALTER TABLE songs
+    ALTER COLUMN enrichment_status SET DEFAULT 'pending';
+CREATE INDEX IF NOT EXISTS songs_enrichment_status_idx ON songs (enrichment_status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_enrichment_status_idx;
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS enrichment_status;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE songs
+    ADD COLUMN enriched_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS songs_enriched_at_idx ON songs (enriched_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_enriched_at_idx;
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS enriched_at;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS song_info_cache (
+    key VARCHAR(600) PRIMARY KEY,
+    release_date VARCHAR(255),
+    text TEXT,
+    link VARCHAR(255),
+    not_found BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS song_info_cache_expires_at_idx ON song_info_cache (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS song_info_cache;
+-- +goose StatementEnd
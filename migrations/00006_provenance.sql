@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Источник каждого поля песни: {"release_date": "<provider>", "text": "<provider>", "link": "<provider>"}
+ALTER TABLE songs
+    ADD COLUMN provenance JSONB;
+ALTER TABLE song_info_cache
+    ADD COLUMN provenance JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE song_info_cache
+    DROP COLUMN IF EXISTS provenance;
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS provenance;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Дата выхода хранится строкой в формате YYYY-MM-DD или DD.MM.YYYY (как отдаёт внешнее API).
+-- song_release_date приводит её к DATE для фильтрации по диапазону, нераспознанное значение - NULL
+CREATE OR REPLACE FUNCTION song_release_date(value TEXT) RETURNS DATE
+    LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE AS
+$$
+BEGIN
+    IF value ~ '^\d{4}-\d{2}-\d{2}$' THEN
+        RETURN to_date(value, 'YYYY-MM-DD');
+    ELSIF value ~ '^\d{2}\.\d{2}\.\d{4}$' THEN
+        RETURN to_date(value, 'DD.MM.YYYY');
+    END IF;
+    RETURN NULL;
+EXCEPTION
+    WHEN others THEN
+        RETURN NULL;
+END;
+$$;
+CREATE INDEX IF NOT EXISTS songs_release_date_idx ON songs (song_release_date(release_date));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_release_date_idx;
+DROP FUNCTION IF EXISTS song_release_date(TEXT);
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Индексы под сортировку GET /songs: поле сортировки и id для однозначного порядка.
+-- Обратный порядок (-group, -name, -release_date) читается тем же индексом в обратную сторону
+CREATE INDEX IF NOT EXISTS songs_group_name_sort_idx ON songs (group_name, name, id);
+CREATE INDEX IF NOT EXISTS songs_name_sort_idx ON songs (name, id);
+CREATE INDEX IF NOT EXISTS songs_release_date_sort_idx ON songs (song_release_date(release_date), id);
+DROP INDEX IF EXISTS songs_release_date_idx;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS songs_release_date_idx ON songs (song_release_date(release_date));
+DROP INDEX IF EXISTS songs_release_date_sort_idx;
+DROP INDEX IF EXISTS songs_name_sort_idx;
+DROP INDEX IF EXISTS songs_group_name_sort_idx;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Полнотекстовый поиск по группе, названию (вес A) и тексту песни (вес B). Конфигурация simple
+-- не зависит от языка: тексты песен бывают на разных языках
+ALTER TABLE songs
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(group_name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(text, '')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS songs_search_vector_idx ON songs USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_search_vector_idx;
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
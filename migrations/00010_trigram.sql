@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Поиск с опечатками по группе и названию (fuzzy=true, "did you mean"). Те же индексы ускоряют
+-- поиск подстроки через ILIKE в фильтрах group и name
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS songs_group_name_trgm_idx ON songs USING GIN (group_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS songs_name_trgm_idx ON songs USING GIN (name gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_name_trgm_idx;
+DROP INDEX IF EXISTS songs_group_name_trgm_idx;
+-- +goose StatementEnd
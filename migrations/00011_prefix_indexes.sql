@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Индексы для автодополнения (GET /suggest): поиск по началу строки без учёта регистра
+CREATE INDEX IF NOT EXISTS songs_group_name_prefix_idx ON songs (lower(group_name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS songs_name_prefix_idx ON songs (lower(name) text_pattern_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_name_prefix_idx;
+DROP INDEX IF EXISTS songs_group_name_prefix_idx;
+-- +goose StatementEnd
@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS artists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE
+);
+
+INSERT INTO artists (name)
+SELECT DISTINCT group_name FROM songs
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE songs
+    ADD COLUMN artist_id INTEGER REFERENCES artists (id) ON DELETE RESTRICT;
+UPDATE songs SET artist_id = artists.id FROM artists WHERE artists.name = songs.group_name;
+ALTER TABLE songs
+    ALTER COLUMN artist_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS songs_artist_id_idx ON songs (artist_id, id);
+
+-- songs.group_name остаётся денормализованной копией artists.name: по ней работают фильтры,
+-- сортировка и поиск, а API песен принимает и отдаёт группу по имени. Триггеры поддерживают
+-- копию: новая группа песни находит или создаёт исполнителя, переименование исполнителя
+-- обновляет все его песни
+CREATE OR REPLACE FUNCTION songs_sync_artist() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.artist_id IS DISTINCT FROM OLD.artist_id
+        AND NEW.group_name IS NOT DISTINCT FROM OLD.group_name THEN
+        SELECT name INTO NEW.group_name FROM artists WHERE id = NEW.artist_id;
+        RETURN NEW;
+    END IF;
+
+    SELECT id INTO NEW.artist_id FROM artists WHERE name = NEW.group_name;
+    IF NOT FOUND THEN
+        INSERT INTO artists (name) VALUES (NEW.group_name)
+        ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+        RETURNING id INTO NEW.artist_id;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+CREATE TRIGGER songs_sync_artist
+    BEFORE INSERT OR UPDATE OF group_name, artist_id ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_sync_artist();
+
+CREATE OR REPLACE FUNCTION artists_sync_songs() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE songs SET group_name = NEW.name WHERE artist_id = NEW.id;
+    RETURN NULL;
+END;
+$$;
+CREATE TRIGGER artists_sync_songs
+    AFTER UPDATE OF name ON artists
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION artists_sync_songs();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS artists_sync_songs ON artists;
+DROP FUNCTION IF EXISTS artists_sync_songs();
+DROP TRIGGER IF EXISTS songs_sync_artist ON songs;
+DROP FUNCTION IF EXISTS songs_sync_artist();
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS artist_id;
+DROP TABLE IF EXISTS artists;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    artist_id INTEGER NOT NULL REFERENCES artists (id) ON DELETE RESTRICT,
+    title VARCHAR(255) NOT NULL,
+    release_date VARCHAR(255) NOT NULL DEFAULT '',
+    cover VARCHAR(255) NOT NULL DEFAULT '',
+    UNIQUE (artist_id, title)
+);
+
+-- Песня входит не больше чем в один альбом. Номер диска есть у каждого трека альбома,
+-- номер трека может быть неизвестен - такие треки идут в конце диска
+ALTER TABLE songs
+    ADD COLUMN album_id INTEGER REFERENCES albums (id),
+    ADD COLUMN disc_number INTEGER CHECK (disc_number > 0),
+    ADD COLUMN track_number INTEGER CHECK (track_number > 0),
+    ADD CONSTRAINT songs_album_track_check CHECK (
+        (album_id IS NULL AND disc_number IS NULL AND track_number IS NULL)
+        OR (album_id IS NOT NULL AND disc_number IS NOT NULL)
+    );
+CREATE UNIQUE INDEX IF NOT EXISTS songs_album_track_idx ON songs (album_id, disc_number, track_number);
+CREATE INDEX IF NOT EXISTS albums_title_idx ON albums (title, id);
+
+-- Альбом, найденный внешним API, кэшируется вместе с остальными деталями песни
+ALTER TABLE song_info_cache
+    ADD COLUMN album JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE song_info_cache
+    DROP COLUMN IF EXISTS album;
+DROP INDEX IF EXISTS songs_album_track_idx;
+ALTER TABLE songs
+    DROP CONSTRAINT IF EXISTS songs_album_track_check,
+    DROP COLUMN IF EXISTS track_number,
+    DROP COLUMN IF EXISTS disc_number,
+    DROP COLUMN IF EXISTS album_id;
+DROP TABLE IF EXISTS albums;
+-- +goose StatementEnd
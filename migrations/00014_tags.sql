@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Теги хранятся в нижнем регистре без пробелов по краям, поэтому имя уникально без учёта регистра
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS song_tags (
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (song_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS song_tags_tag_id_idx ON song_tags (tag_id, song_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS song_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
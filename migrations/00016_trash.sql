@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Удалённая песня попадает в корзину: deleted_at - время удаления. Из корзины песню можно восстановить,
+-- пока её не удалит навсегда очистка корзины
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS songs_deleted_at_idx ON songs (deleted_at, id) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Без колонки deleted_at песни из корзины снова стали бы видны, поэтому они удаляются
+DELETE FROM songs WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS songs_deleted_at_idx;
+ALTER TABLE songs DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ревизия - полное состояние песни после каждого изменения группы, названия, даты выхода, текста или ссылки.
+-- Автора изменения передаёт транзакция: SELECT set_config('songlib.author', 'имя', true)
+CREATE TABLE IF NOT EXISTS song_revisions (
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL CHECK (revision > 0),
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    group_name VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    release_date VARCHAR(255),
+    text TEXT,
+    link VARCHAR(255),
+    PRIMARY KEY (song_id, revision)
+);
+
+-- Номер ревизии следующий за последним: изменения одной песни выполняются по очереди
+-- под блокировкой её строки, поэтому номера не повторяются
+CREATE OR REPLACE FUNCTION songs_record_revision() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    INSERT INTO song_revisions (song_id, revision, author, group_name, name, release_date, text, link)
+    SELECT NEW.id, COALESCE(MAX(revision), 0) + 1, COALESCE(current_setting('songlib.author', true), ''),
+        NEW.group_name, NEW.name, NEW.release_date, NEW.text, NEW.link
+    FROM song_revisions WHERE song_id = NEW.id;
+    RETURN NULL;
+END;
+$$;
+
+-- CREATE OR REPLACE TRIGGER есть только с PostgreSQL 14, поэтому триггеры пересоздаются
+DROP TRIGGER IF EXISTS songs_record_revision_insert ON songs;
+CREATE TRIGGER songs_record_revision_insert
+    AFTER INSERT ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_record_revision();
+DROP TRIGGER IF EXISTS songs_record_revision_update ON songs;
+CREATE TRIGGER songs_record_revision_update
+    AFTER UPDATE OF group_name, name, release_date, text, link ON songs
+    FOR EACH ROW
+    WHEN ((OLD.group_name, OLD.name, OLD.release_date, OLD.text, OLD.link)
+        IS DISTINCT FROM (NEW.group_name, NEW.name, NEW.release_date, NEW.text, NEW.link))
+    EXECUTE FUNCTION songs_record_revision();
+
+-- Текущее состояние существующих песен становится их первой ревизией
+INSERT INTO song_revisions (song_id, revision, group_name, name, release_date, text, link)
+SELECT id, 1, group_name, name, release_date, text, link FROM songs
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS songs_record_revision_update ON songs;
+DROP TRIGGER IF EXISTS songs_record_revision_insert ON songs;
+DROP FUNCTION IF EXISTS songs_record_revision();
+DROP TABLE IF EXISTS song_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сортировка sort=group добавляет id сразу после group_name, а индекс songs_group_name_sort_idx
+-- построен по (group_name, name, id) и такой порядок не покрывает
+CREATE INDEX IF NOT EXISTS songs_group_sort_idx ON songs (group_name, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_group_sort_idx;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Песня из корзины не занимает место в альбоме: номер трека уникален только среди неудалённых песен
+DROP INDEX IF EXISTS songs_album_track_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS songs_album_track_idx ON songs (album_id, disc_number, track_number) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Песни из корзины, занявшие место неудалённой песни, теряют номер трека
+UPDATE songs SET track_number = NULL
+WHERE deleted_at IS NOT NULL AND EXISTS (
+    SELECT 1 FROM songs t
+    WHERE t.id <> songs.id AND t.album_id = songs.album_id AND t.disc_number = songs.disc_number AND t.track_number = songs.track_number
+        AND (t.deleted_at IS NULL OR t.id < songs.id)
+);
+DROP INDEX IF EXISTS songs_album_track_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS songs_album_track_idx ON songs (album_id, disc_number, track_number);
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Альбом принадлежит одному исполнителю: песня, сменившая группу, покидает альбом прежнего
+-- исполнителя вместе с номером диска и трека
+CREATE OR REPLACE FUNCTION songs_sync_artist() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.artist_id IS DISTINCT FROM OLD.artist_id
+        AND NEW.group_name IS NOT DISTINCT FROM OLD.group_name THEN
+        SELECT name INTO NEW.group_name FROM artists WHERE id = NEW.artist_id;
+    ELSE
+        SELECT id INTO NEW.artist_id FROM artists WHERE name = NEW.group_name;
+        IF NOT FOUND THEN
+            INSERT INTO artists (name) VALUES (NEW.group_name)
+            ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+            RETURNING id INTO NEW.artist_id;
+        END IF;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND NEW.artist_id IS DISTINCT FROM OLD.artist_id THEN
+        NEW.album_id := NULL;
+        NEW.disc_number := NULL;
+        NEW.track_number := NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION songs_sync_artist() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.artist_id IS DISTINCT FROM OLD.artist_id
+        AND NEW.group_name IS NOT DISTINCT FROM OLD.group_name THEN
+        SELECT name INTO NEW.group_name FROM artists WHERE id = NEW.artist_id;
+        RETURN NEW;
+    END IF;
+
+    SELECT id INTO NEW.artist_id FROM artists WHERE name = NEW.group_name;
+    IF NOT FOUND THEN
+        INSERT INTO artists (name) VALUES (NEW.group_name)
+        ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+        RETURNING id INTO NEW.artist_id;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd